@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/shammianand/picalc/pkg/picalc"
+	"github.com/shammianand/picalc/pkg/render"
+	"github.com/spf13/cobra"
+)
+
+func newAudioCmd() *cobra.Command {
+	var audioCmd = &cobra.Command{
+		Use:   "audio [digits]",
+		Short: "Render π digits as music in a WAV file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			digits, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || digits < 1 {
+				return fmt.Errorf("digits must be a positive integer")
+			}
+
+			out, _ := cmd.Flags().GetString("out")
+
+			pi := picalc.NewPi(digits)
+			if err := picalc.CalculatePiContext(context.Background(), digits, pi); err != nil {
+				return err
+			}
+			piDigits := pi.GetDigits(int(digits) + 1)
+
+			f, err := os.Create(out)
+			if err != nil {
+				return fmt.Errorf("error creating file: %v", err)
+			}
+			defer f.Close()
+
+			w := bufio.NewWriter(f)
+			if err := render.RenderDigitsWAV(piDigits, w); err != nil {
+				return fmt.Errorf("error writing audio: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				return fmt.Errorf("error writing audio: %v", err)
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("error closing file: %v", err)
+			}
+
+			length := time.Duration(len(piDigits)) * render.NoteDuration
+			fmt.Printf("Audio (%d notes, %v) saved to %s\n", len(piDigits), length, out)
+			return nil
+		},
+	}
+
+	audioCmd.Flags().String("out", "pi.wav", "Output WAV file")
+
+	return audioCmd
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/shammianand/picalc/pkg/picalc"
+)
+
+// batchResult is the JSON line written for each precision read in batch mode
+type batchResult struct {
+	Precision      int64  `json:"precision"`
+	Digits         string `json:"digits"`
+	Duration       string `json:"duration"`
+	Checksum       string `json:"checksum"`
+	ReliableDigits int    `json:"reliable_digits"`
+}
+
+// batchError is the JSON line written for a line that couldn't be calculated
+type batchError struct {
+	Input string `json:"input"`
+	Error string `json:"error"`
+}
+
+// batchCacheDigits bounds the total digits of the results runBatch keeps
+// for repeated precisions
+const batchCacheDigits = 10_000_000
+
+// batchCache holds recent batch results by precision, dropping the oldest
+// once they hold more than batchCacheDigits digits in total
+type batchCache struct {
+	results map[int64]batchResult
+	order   []int64 // oldest first
+	digits  int
+}
+
+// add caches res, evicting the oldest results beyond batchCacheDigits.
+// A result larger than the whole budget isn't cached.
+func (c *batchCache) add(res batchResult) {
+	if len(res.Digits) > batchCacheDigits {
+		return
+	}
+
+	c.results[res.Precision] = res
+	c.order = append(c.order, res.Precision)
+	c.digits += len(res.Digits)
+
+	for c.digits > batchCacheDigits {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		c.digits -= len(c.results[oldest].Digits)
+		delete(c.results, oldest)
+	}
+}
+
+// runBatch reads one precision per line from r and writes a JSON line to w
+// for each, a batchResult or a batchError, carrying on past bad lines.
+// Repeated precisions are answered from recent results where possible.
+func runBatch(r io.Reader, w io.Writer) error {
+	cache := &batchCache{results: make(map[int64]batchResult)}
+	enc := json.NewEncoder(w)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		res, err := batchLine(line, cache)
+		if err != nil {
+			err = enc.Encode(batchError{Input: line, Error: err.Error()})
+		} else {
+			err = enc.Encode(res)
+		}
+		if err != nil {
+			return fmt.Errorf("error writing result: %v", err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading precisions: %v", err)
+	}
+	return nil
+}
+
+// batchLine calculates the precision on line, or takes it from cache
+func batchLine(line string, cache *batchCache) (batchResult, error) {
+	precision, err := strconv.ParseInt(line, 10, 64)
+	if err != nil || precision < 1 {
+		return batchResult{}, fmt.Errorf("precision must be a positive integer")
+	}
+	if precision > picalc.MaxPrecision {
+		return batchResult{}, fmt.Errorf("precision must be at most %d", picalc.MaxPrecision)
+	}
+	if res, ok := cache.results[precision]; ok {
+		return res, nil
+	}
+
+	result, err := picalc.CalculatePiResult(precision)
+	if err != nil {
+		return batchResult{}, err
+	}
+
+	res := batchResult{
+		Precision:      result.Precision,
+		Digits:         picalc.FormatGrouped(result.Digits, 0, ""),
+		Duration:       result.Duration.String(),
+		Checksum:       result.Checksum,
+		ReliableDigits: result.ReliableDigits,
+	}
+	cache.add(res)
+	return res, nil
+}
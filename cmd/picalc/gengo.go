@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/shammianand/picalc/pkg/picalc"
+	"github.com/spf13/cobra"
+)
+
+func newGenGoCmd() *cobra.Command {
+	var genGoCmd = &cobra.Command{
+		Use:   "gen-go",
+		Short: "Generate a Go source file with π as a string constant",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pkg, _ := cmd.Flags().GetString("package")
+			varName, _ := cmd.Flags().GetString("var")
+			digits, _ := cmd.Flags().GetInt64("digits")
+			out, _ := cmd.Flags().GetString("out")
+
+			if digits < 1 {
+				return fmt.Errorf("--digits must be a positive integer, got %d", digits)
+			}
+
+			pi := picalc.NewPi(digits)
+			if err := picalc.CalculatePiContext(context.Background(), digits, pi); err != nil {
+				return err
+			}
+
+			var buf bytes.Buffer
+			if err := picalc.GenerateGoSource(pi.GetDigits(int(digits)+1), pkg, varName, &buf); err != nil {
+				return err
+			}
+			if err := os.WriteFile(out, buf.Bytes(), 0644); err != nil {
+				return fmt.Errorf("error writing file: %v", err)
+			}
+
+			fmt.Printf("Go source with %d digits saved to %s\n", digits, out)
+			return nil
+		},
+	}
+
+	genGoCmd.Flags().String("package", "main", "Package name of the generated file")
+	genGoCmd.Flags().String("var", "PiDigits", "Name of the generated constant")
+	genGoCmd.Flags().Int64("digits", 100, "Number of decimal places")
+	genGoCmd.Flags().String("out", "pi_gen.go", "File to write")
+
+	return genGoCmd
+}
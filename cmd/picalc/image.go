@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"image/png"
+	"os"
+	"strconv"
+
+	"github.com/shammianand/picalc/pkg/picalc"
+	"github.com/shammianand/picalc/pkg/render"
+	"github.com/spf13/cobra"
+)
+
+func newImageCmd() *cobra.Command {
+	var imageCmd = &cobra.Command{
+		Use:   "image [digits]",
+		Short: "Render π digits as a PNG image",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			digits, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || digits < 1 {
+				return fmt.Errorf("digits must be a positive integer")
+			}
+
+			out, _ := cmd.Flags().GetString("out")
+			width, _ := cmd.Flags().GetInt("width")
+
+			pi := picalc.NewPi(digits)
+			if err := picalc.CalculatePiContext(context.Background(), digits, pi); err != nil {
+				return err
+			}
+
+			img := render.RenderDigitsImage(pi.GetDigits(int(digits)+1), width)
+
+			f, err := os.Create(out)
+			if err != nil {
+				return fmt.Errorf("error creating file: %v", err)
+			}
+			defer f.Close()
+
+			if err := png.Encode(f, img); err != nil {
+				return fmt.Errorf("error encoding image: %v", err)
+			}
+
+			b := img.Bounds()
+			fmt.Printf("Image (%dx%d) saved to %s\n", b.Dx(), b.Dy(), out)
+			return nil
+		},
+	}
+
+	imageCmd.Flags().String("out", "pi.png", "Output PNG file")
+	imageCmd.Flags().Int("width", 100, "Image width in pixels (one digit per pixel)")
+
+	return imageCmd
+}
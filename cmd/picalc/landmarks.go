@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/shammianand/picalc/pkg/picalc"
+	"github.com/spf13/cobra"
+)
+
+func newLandmarksCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "landmarks [digits]",
+		Short: "Find notable patterns such as the Feynman point in π's digits",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			digits, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || digits < 1 {
+				return fmt.Errorf("digits must be a positive integer")
+			}
+
+			pi := picalc.NewPi(digits)
+			if err := picalc.CalculatePiContext(context.Background(), digits, pi); err != nil {
+				return err
+			}
+
+			for _, l := range picalc.FindLandmarks(pi.GetDigits(int(digits) + 1)) {
+				fmt.Printf("%-28s position %-8d %s\n", l.Name+":", l.Position, l.Digits)
+			}
+			return nil
+		},
+	}
+}
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
 	"strconv"
 	"time"
 
-	"github.com/schollz/progressbar/v3"
 	"github.com/shammianand/picalc/pkg/picalc"
 	"github.com/spf13/cobra"
 )
@@ -20,27 +24,232 @@ func main() {
 	}
 
 	var calculateCmd = &cobra.Command{
-		Use:   "calculate [digits]",
+		Use:   "calculate [digits|-]",
 		Short: "Calculate π to the specified number of digits",
-		Args:  cobra.ExactArgs(1),
+		Long:  "Calculates π to the specified number of digits. Given -, reads one precision per line from stdin and writes a JSON line for each.",
+		Args:  cobra.RangeArgs(0, 1),
 		Run: func(cmd *cobra.Command, args []string) {
-			digits, err := strconv.ParseInt(args[0], 10, 64)
-			if err != nil {
-				fmt.Println("Error: digits must be a valid integer")
+			accuracy, _ := cmd.Flags().GetFloat64("accuracy")
+
+			var digits int64
+			switch {
+			case len(args) == 1 && accuracy > 0:
+				fmt.Println("Error: give either digits or --accuracy, not both")
+				os.Exit(1)
+			case len(args) == 0 && accuracy > 0:
+				digits = picalc.DigitsForAccuracy(accuracy)
+				fmt.Printf("A relative error of %g needs %d digits\n", accuracy, digits)
+			case len(args) == 0:
+				fmt.Println("Error: digits or --accuracy is required")
+				os.Exit(1)
+			case args[0] == "-":
+				if err := runBatch(os.Stdin, os.Stdout); err != nil {
+					fmt.Fprintln(os.Stderr, "Error:", err)
+					os.Exit(1)
+				}
+				return
+			default:
+				var err error
+				digits, err = strconv.ParseInt(args[0], 10, 64)
+				if err != nil {
+					fmt.Println("Error: digits must be a valid integer")
+					os.Exit(1)
+				}
+			}
+
+			var opts calculateOptions
+			opts.outputFile, _ = cmd.Flags().GetString("output")
+			opts.showProgress, _ = cmd.Flags().GetBool("progress")
+			opts.progressInterval, _ = cmd.Flags().GetDuration("progress-interval")
+			opts.writeManifest, _ = cmd.Flags().GetBool("manifest")
+			opts.validate, _ = cmd.Flags().GetBool("validate")
+			opts.groupSize, _ = cmd.Flags().GetInt("group-size")
+			opts.groupSep, _ = cmd.Flags().GetString("group-sep")
+			opts.verbose, _ = cmd.Flags().GetBool("verbose")
+			opts.fractionalOnly, _ = cmd.Flags().GetBool("fractional-only")
+			opts.fast, _ = cmd.Flags().GetBool("fast")
+			opts.noHardcode, _ = cmd.Flags().GetBool("no-hardcode")
+			opts.tau, _ = cmd.Flags().GetBool("tau")
+			opts.stride, _ = cmd.Flags().GetInt("stride")
+			opts.lineWidth, _ = cmd.Flags().GetInt("line-width")
+			opts.lineEnding, _ = cmd.Flags().GetString("line-ending")
+			decimalSep, _ := cmd.Flags().GetString("decimal-sep")
+			opts.cpuProfile, _ = cmd.Flags().GetString("cpuprofile")
+			opts.memProfile, _ = cmd.Flags().GetString("memprofile")
+			opts.verifyWrite, _ = cmd.Flags().GetBool("verify-write")
+			opts.start, _ = cmd.Flags().GetInt("start")
+			opts.encoding, _ = cmd.Flags().GetString("encoding")
+			if !cmd.Flags().Changed("encoding") && cmd.Flags().Changed("output-format") {
+				// The deprecated --output-format takes text or binary
+				if format, _ := cmd.Flags().GetString("output-format"); format != "text" {
+					opts.encoding = format
+				}
+			}
+			opts.reference, _ = cmd.Flags().GetString("reference")
+			opts.histogramCSV, _ = cmd.Flags().GetString("histogram-csv")
+			opts.preview, _ = cmd.Flags().GetInt("preview")
+			opts.paranoid, _ = cmd.Flags().GetBool("paranoid")
+			opts.timeout, _ = cmd.Flags().GetDuration("timeout")
+			maxProcs, _ := cmd.Flags().GetInt("max-procs")
+			algorithmName, _ := cmd.Flags().GetString("algorithm")
+
+			if opts.stride > 1 && opts.writeManifest {
+				fmt.Println("Error: --stride cannot be combined with --manifest")
+				os.Exit(1)
+			}
+			if opts.lineEnding != "lf" && opts.lineEnding != "crlf" {
+				fmt.Println("Error: --line-ending must be lf or crlf")
+				os.Exit(1)
+			}
+			if sep, err := picalc.ParseDecimalSep(decimalSep); err != nil {
+				fmt.Println("Error: --decimal-sep:", err)
+				os.Exit(1)
+			} else if sep != '.' {
+				opts.decimalSep = sep
+			}
+			switch opts.encoding {
+			case "ascii", "binary", "packed", "json":
+			default:
+				fmt.Println("Error: --encoding must be ascii, binary, packed or json")
+				os.Exit(1)
+			}
+			if opts.decimalSep != 0 && (opts.encoding != "ascii" || opts.writeManifest) {
+				fmt.Println("Error: --decimal-sep applies only to ascii output without --manifest")
+				os.Exit(1)
+			}
+			if opts.encoding != "ascii" && opts.writeManifest {
+				fmt.Printf("Error: --encoding %s cannot be combined with --manifest\n", opts.encoding)
+				os.Exit(1)
+			}
+			if opts.encoding != "ascii" && (opts.fractionalOnly || opts.lineWidth > 0) {
+				fmt.Printf("Error: --encoding %s cannot be combined with --fractional-only or --line-width\n", opts.encoding)
+				os.Exit(1)
+			}
+			if opts.encoding == "json" && (opts.stride > 1 || opts.verifyWrite) {
+				fmt.Println("Error: --encoding json cannot be combined with --stride or --verify-write")
+				os.Exit(1)
+			}
+			if opts.tau && (opts.paranoid || opts.validate || opts.reference != "") {
+				fmt.Println("Error: --paranoid, --validate and --reference check against π and cannot be combined with --tau")
+				os.Exit(1)
+			}
+			if opts.start > 0 && opts.stride > 1 {
+				fmt.Println("Error: --start cannot be combined with --stride")
+				os.Exit(1)
+			}
+			if opts.start > 0 && int64(opts.start) >= digits {
+				fmt.Printf("Error: --start must be less than the %d digits calculated\n", digits)
+				os.Exit(1)
+			}
+
+			if opts.outputFile != "" {
+				if err := checkOutputFile(opts.outputFile); err != nil {
+					fmt.Println("Error:", err)
+					os.Exit(1)
+				}
+			}
+
+			var algorithm picalc.Algorithm
+			switch algorithmName {
+			case "chudnovsky":
+				algorithm = picalc.Chudnovsky
+			case "ramanujan":
+				algorithm = picalc.Ramanujan
+			case "spigot":
+				algorithm = picalc.Spigot
+			case "auto":
+				algorithm = picalc.Auto
+			default:
+				fmt.Println("Error: --algorithm must be chudnovsky, ramanujan, spigot or auto")
 				os.Exit(1)
 			}
 
-			outputFile, _ := cmd.Flags().GetString("output")
-			showProgress, _ := cmd.Flags().GetBool("progress")
+			procs := setMaxProcs(maxProcs)
+			fmt.Printf("Using %d CPU cores\n", procs)
+			if procs < 2 {
+				fmt.Println("Warning: running single-threaded; binary splitting will not be parallel")
+			}
 
-			calculatePi(digits, outputFile, showProgress)
+			pi := picalc.NewPiWithOptions(digits, picalc.Options{
+				RecordTimings: opts.verbose,
+				Fast:          opts.fast,
+				NoHardcode:    opts.noHardcode,
+				Algorithm:     algorithm,
+			})
+
+			// Update progress if enabled, as a bar on a terminal or as text lines otherwise
+			var progress progressRenderer
+			var interval time.Duration
+			if opts.showProgress {
+				progress, interval = newProgressRenderer(opts.progressInterval)
+			}
+
+			// Ctrl-C cancels the calculation instead of killing the process
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			err := calculatePi(ctx, pi, digits, opts, progress, interval)
+			stop()
+
+			if errors.Is(err, errInterrupted) {
+				os.Exit(130)
+			}
+			if errors.Is(err, errTimedOut) {
+				os.Exit(124)
+			}
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
 		},
 	}
 
 	calculateCmd.Flags().StringP("output", "o", "", "Save digits to file")
 	calculateCmd.Flags().BoolP("progress", "p", true, "Show progress bar")
+	calculateCmd.Flags().Duration("progress-interval", 0, "Print a progress line at this interval instead of a bar (default: bar on a terminal, every 5s otherwise)")
+	calculateCmd.Flags().Bool("manifest", false, "Write a JSON manifest next to the output file")
+	calculateCmd.Flags().Bool("validate", false, "Validate digits against the embedded reference")
+	calculateCmd.Flags().Int("group-size", 0, "Group displayed digits into blocks of this size (0 disables)")
+	calculateCmd.Flags().String("group-sep", " ", "Separator between digit groups")
+	calculateCmd.Flags().Int("max-procs", 0, "Maximum CPU cores to use (0 uses the GOMAXPROCS default)")
+	calculateCmd.Flags().BoolP("verbose", "v", false, "Print time spent in each phase")
+	calculateCmd.Flags().Bool("fractional-only", false, "Omit the leading \"3.\" from output")
+	calculateCmd.Flags().Bool("fast", false, "Skip recomputing with extra guard digits to verify the last digits")
+	calculateCmd.Flags().Bool("no-hardcode", false, "Run the algorithm even for 10 or fewer digits instead of using a hardcoded value")
+	calculateCmd.Flags().String("algorithm", "chudnovsky", "Algorithm: chudnovsky, ramanujan for comparison (slower), spigot (only fast for a few dozen digits), or auto to pick by precision")
+	calculateCmd.Flags().Bool("paranoid", false, "Recompute with a second algorithm and keep only the digits both agree on")
+	calculateCmd.Flags().Duration("timeout", 0, "Give up after this long, e.g. 30s or 1h (0 waits until done)")
+	calculateCmd.Flags().Bool("tau", false, "Calculate τ (2π) instead of π")
+	calculateCmd.Flags().Int("stride", 1, "Output only every k-th digit, starting with the integer part")
+	calculateCmd.Flags().Int("line-width", 0, "Start a new line every N fractional digits in the output file (0 disables)")
+	calculateCmd.Flags().String("line-ending", "lf", "Line ending used with --line-width: lf, or crlf for Windows")
+	calculateCmd.Flags().String("decimal-sep", ".", "Decimal separator in the output and preview, e.g. \",\" for 3,14159")
+	calculateCmd.Flags().String("cpuprofile", "", "Write a pprof CPU profile of the computation to this file")
+	calculateCmd.Flags().String("memprofile", "", "Write a pprof memory profile after the computation to this file")
+	calculateCmd.Flags().Bool("verify-write", false, "Read the output file back and check its digits and checksum")
+	calculateCmd.Flags().Float64("accuracy", 0, "Calculate enough digits for this relative error instead of a digit count, e.g. 1e-15")
+	calculateCmd.Flags().Int("preview", 100, "Fractional digits to print when no output file is given (0 shows all)")
+	calculateCmd.Flags().String("histogram-csv", "", "Write the frequencies of the fractional digits to this file as CSV")
+	calculateCmd.Flags().String("reference", "", "Compare the computed digits against a trusted digits file")
+	calculateCmd.Flags().String("encoding", "ascii", "Output file encoding: ascii, binary (or packed) with two digits per byte, or json")
+	calculateCmd.Flags().String("output-format", "text", "Output file format: text or binary")
+	calculateCmd.Flags().MarkDeprecated("output-format", "use --encoding instead")
+	calculateCmd.Flags().Int("start", 0, "Display digits starting at this position (0 is the leading 3)")
 
 	rootCmd.AddCommand(calculateCmd)
+	rootCmd.AddCommand(newImageCmd())
+	rootCmd.AddCommand(newAudioCmd())
+	rootCmd.AddCommand(newServeCmd())
+	rootCmd.AddCommand(newVersionCmd())
+	rootCmd.AddCommand(newProfileCmd())
+	rootCmd.AddCommand(newThroughputCmd())
+	rootCmd.AddCommand(newVectorsCmd())
+	rootCmd.AddCommand(newLandmarksCmd())
+	rootCmd.AddCommand(newPartitionCmd())
+	rootCmd.AddCommand(newGenGoCmd())
+	rootCmd.AddCommand(newSelfTestCmd())
+	rootCmd.AddCommand(newCompletionCmd(rootCmd))
+
+	// Replaced by newCompletionCmd, which limits the shells to those we support
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -48,52 +257,130 @@ func main() {
 	}
 }
 
-func calculatePi(digits int64, outputFile string, showProgress bool) {
-	fmt.Printf("Calculating π to %d decimal digits...\n", digits)
-	startTime := time.Now()
+// setMaxProcs applies a --max-procs value to GOMAXPROCS and returns the
+// effective core count. Values of 0 or less keep the current setting.
+func setMaxProcs(n int) int {
+	if n > 0 {
+		runtime.GOMAXPROCS(n)
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// calculateOptions holds the calculate command flags
+type calculateOptions struct {
+	outputFile       string
+	encoding         string
+	showProgress     bool
+	progressInterval time.Duration
+	timeout          time.Duration
+	writeManifest    bool
+	validate         bool
+	verifyWrite      bool
+	verbose          bool
+	paranoid         bool
+	fast             bool
+	noHardcode       bool
+	tau              bool
+	fractionalOnly   bool
+	groupSize        int
+	stride           int
+	start            int
+	lineWidth        int
+	lineEnding       string
+	decimalSep       byte
+	preview          int
+	cpuProfile       string
+	memProfile       string
+	reference        string
+	histogramCSV     string
+	groupSep         string
+}
 
-	// Create a progress tracker if enabled
-	var bar *progressbar.ProgressBar
-	var progressSignal chan struct{}
+// errInterrupted is returned by calculatePi when ctx is cancelled, after
+// it has reported how far the calculation got
+var errInterrupted = errors.New("calculation interrupted")
 
-	if showProgress {
-		bar = progressbar.DefaultBytes(
-			digits,
-			"Computing",
-		)
-		progressSignal = make(chan struct{})
+// errTimedOut is returned by calculatePi when opts.timeout passes before the
+// calculation finishes, after it has reported how far it got
+var errTimedOut = errors.New("calculation timed out")
+
+// calculatePi calculates digits of pi, or of τ with opts.tau, until done or
+// ctx is cancelled or opts.timeout passes, then reports and writes the
+// results as opts asks. Progress is shown on progress every interval unless
+// progress is nil.
+func calculatePi(ctx context.Context, pi *picalc.Pi, digits int64, opts calculateOptions, progress progressRenderer, interval time.Duration) error {
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
 	}
 
-	// Start Pi calculation in a goroutine
-	pi := picalc.NewPi(digits)
-	done := make(chan struct{})
+	symbol := "π"
+	if opts.tau {
+		symbol = "τ"
+	}
+	fmt.Printf("Calculating %s to %d decimal digits...\n", symbol, digits)
+	startTime := time.Now()
 
+	stopProfiling, err := startProfiling(opts.cpuProfile, opts.memProfile)
+	if err != nil {
+		return err
+	}
+
+	// Start Pi calculation in a goroutine
+	done := make(chan error, 1)
 	go func() {
-		picalc.CalculatePi(digits, pi)
-		close(done)
+		if opts.tau {
+			done <- picalc.CalculateTauContext(ctx, digits, pi)
+		} else {
+			done <- picalc.CalculatePiContext(ctx, digits, pi)
+		}
 	}()
 
-	// Update progress if enabled
-	if showProgress {
-		go func() {
-			for {
-				select {
-				case <-progressSignal:
-					return
-				default:
-					progress := pi.GetProgress()
-					bar.Set64(int64(float64(digits) * progress / 100.0))
-					time.Sleep(100 * time.Millisecond)
-				}
-			}
-		}()
+	stopProgress := func(bool) {}
+	if progress != nil {
+		stopProgress = runProgress(progress, interval, pi)
+	}
+
+	// Wait for completion, or stop waiting as soon as the user interrupts in
+	// case the computation is in a phase that can't be cancelled.
+	var calcErr error
+	select {
+	case calcErr = <-done:
+	case <-ctx.Done():
+		calcErr = context.Cause(ctx)
 	}
+	stopProgress(calcErr == nil)
 
-	// Wait for completion
-	<-done
-	if showProgress {
-		close(progressSignal)
-		bar.Finish()
+	if err := stopProfiling(); err != nil {
+		return err
+	}
+
+	if errors.Is(calcErr, context.Canceled) || errors.Is(calcErr, context.DeadlineExceeded) {
+		timedOut := errors.Is(calcErr, context.DeadlineExceeded)
+		if timedOut {
+			// The series yields no digits until it is summed, so this is the
+			// share of the requested digits its completed terms are worth
+			fmt.Printf("\nTimed out after %v, computed ~%d digits (%.1f%%)\n",
+				opts.timeout, int64(pi.GetProgress()/100*float64(digits)), pi.GetProgress())
+		} else {
+			fmt.Printf("\nInterrupted at %.1f%%\n", pi.GetProgress())
+		}
+		if opts.outputFile != "" {
+			path := partialPath(opts.outputFile)
+			if err := writePartial(path, pi, time.Since(startTime)); err != nil {
+				fmt.Println("Error:", err)
+			} else {
+				fmt.Printf("Partial results saved to %s\n", path)
+			}
+		}
+		if timedOut {
+			return errTimedOut
+		}
+		return errInterrupted
+	}
+	if calcErr != nil {
+		return calcErr
 	}
 
 	// Get all digits
@@ -103,19 +390,168 @@ func calculatePi(digits int64, outputFile string, showProgress bool) {
 	duration := time.Since(startTime)
 	fmt.Printf("\nCalculation completed in %v\n", duration)
 
+	if unreliable := int(digits) + 1 - pi.ReliableDigits(); unreliable > 0 {
+		fmt.Printf("Warning: the last %d digits may be unreliable\n", unreliable)
+	}
+
+	if opts.verbose {
+		t := pi.LastTimings()
+		fmt.Printf("  binary split: %v\n", t.BinarySplit)
+		fmt.Printf("  sqrt:         %v\n", t.Sqrt)
+		fmt.Printf("  division:     %v\n", t.Division)
+		fmt.Printf("  extraction:   %v\n", t.Extraction)
+	}
+
+	// Keep only the digits a second, independent algorithm agrees on
+	if opts.paranoid {
+		agreed, err := picalc.CrossCheck(ctx, pi)
+		if err != nil {
+			return err
+		}
+		if agreed < len(piDigits) {
+			fmt.Printf("Warning: the algorithms agree on only %d of %d digits; keeping those\n", agreed, len(piDigits))
+			piDigits = piDigits[:agreed]
+			digits = int64(agreed)
+		} else {
+			fmt.Printf("Cross-checked %d digits with a second algorithm\n", len(piDigits))
+		}
+	}
+
+	if opts.validate {
+		matched, err := pi.ValidateAgainstReference(len(piDigits))
+		if err != nil {
+			return fmt.Errorf("validation failed: %w", err)
+		}
+		fmt.Printf("Validated %d digits against reference\n", matched)
+	}
+
+	if opts.reference != "" {
+		matched, err := picalc.ValidateAgainstFile(pi, len(piDigits), opts.reference)
+		if err != nil {
+			return fmt.Errorf("validation failed: %w", err)
+		}
+		fmt.Printf("Validated %d digits against %s\n", matched, opts.reference)
+	}
+
+	if opts.histogramCSV != "" {
+		// Count the fractional digits only, not the integer 3
+		if err := writeHistogram(opts.histogramCSV, piDigits[1:]); err != nil {
+			return err
+		}
+		fmt.Printf("Digit histogram saved to %s\n", opts.histogramCSV)
+	}
+
+	// Decimate after validating so every computed digit is checked
+	if opts.stride > 1 {
+		piDigits = pi.GetDigitsStride(int(digits), opts.stride)
+		fmt.Printf("Keeping digits at positions 0, %d, %d, ... (%d digits)\n", opts.stride, 2*opts.stride, len(piDigits))
+	}
+
 	// Output results
-	if outputFile != "" {
-		picalc.WriteDigitsToFile(piDigits, outputFile)
-		fmt.Printf("Results saved to %s\n", outputFile)
-	} else {
-		fmt.Print("π = 3.")
-		for i := 1; i < len(piDigits) && i <= 100; i++ {
-			fmt.Print(piDigits[i])
+	if opts.outputFile != "" {
+		if opts.writeManifest {
+			if err := picalc.WriteDigitsWithManifest(pi, int(digits), opts.outputFile); err != nil {
+				return err
+			}
+			fmt.Printf("Manifest saved to %s\n", picalc.ManifestPath(opts.outputFile))
+		} else {
+			var err error
+			switch opts.encoding {
+			case "ascii":
+				err = picalc.WriteDigitsToFileContext(ctx, piDigits, opts.outputFile, picalc.TextOptions{
+					FractionalOnly: opts.fractionalOnly,
+					LineWidth:      opts.lineWidth,
+					CRLF:           opts.lineEnding == "crlf",
+					DecimalSep:     opts.decimalSep,
+				})
+			case "json":
+				err = picalc.WriteDigits(pi, len(piDigits), opts.outputFile, opts.encoding)
+			default:
+				// Written from piDigits so --stride still applies
+				err = picalc.WriteDigitsBinary(piDigits, opts.outputFile)
+			}
+			if errors.Is(err, context.Canceled) {
+				fmt.Printf("\nInterrupted while writing; %s was not changed\n", opts.outputFile)
+				return errInterrupted
+			}
+			if err != nil {
+				return err
+			}
 		}
-		if len(piDigits) > 100 {
+		fmt.Printf("Results saved to %s\n", opts.outputFile)
+
+		if opts.verifyWrite {
+			if err := picalc.VerifyDigitsFile(piDigits, opts.outputFile); err != nil {
+				return err
+			}
+			fmt.Printf("Verified %d digits in %s\n", len(piDigits), opts.outputFile)
+		}
+	} else if opts.start > 0 {
+		count := len(piDigits) - opts.start
+		if opts.preview > 0 && count > opts.preview {
+			count = opts.preview
+		}
+		window, err := pi.GetDigitsFrom(opts.start, max(count, 0))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Digits %d to %d: %s", opts.start, opts.start+len(window)-1, picalc.FormatPlain(window))
+		if opts.start+len(window) < len(piDigits) {
 			fmt.Print("...")
 		}
 		fmt.Println()
+	} else {
+		if !opts.fractionalOnly {
+			fmt.Print(symbol, " = ")
+		}
+		fmt.Println(formatPreview(piDigits, opts))
 		fmt.Println("Use --output flag to save all digits to a file")
 	}
+
+	return nil
+}
+
+// formatPreview formats the leading 3 and up to opts.preview fractional
+// digits as requested, followed by "..." if any were left out. A preview of
+// 0 or less shows every digit.
+func formatPreview(digits []int, opts calculateOptions) string {
+	preview := digits
+	if opts.preview > 0 && len(preview) > opts.preview+1 {
+		preview = preview[:opts.preview+1]
+	}
+
+	var text string
+	if opts.fractionalOnly {
+		text = picalc.FormatFractional(preview)
+	} else {
+		text = picalc.WithDecimalSep(picalc.FormatGrouped(preview, opts.groupSize, opts.groupSep), opts.decimalSep)
+	}
+	if len(preview) < len(digits) {
+		text += "..."
+	}
+	return text
+}
+
+// checkOutputFile rejects an --output path that can't be written before the
+// calculation starts, suggesting a file inside it when it is a directory
+func checkOutputFile(path string) error {
+	err := picalc.CheckOutputPath(path)
+	if errors.Is(err, picalc.ErrOutputIsDirectory) {
+		return fmt.Errorf("%w; give a filename, e.g. --output %s", err, filepath.Join(path, "pi.txt"))
+	}
+	return err
+}
+
+// writeHistogram writes the digit frequencies of digits to path as CSV. The
+// caller passes the fractional digits so the integer part isn't counted.
+func writeHistogram(path string, digits []int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating histogram file: %v", err)
+	}
+	if err := picalc.WriteDigitHistogramCSV(digits, f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shammianand/picalc/pkg/picalc"
+	"github.com/spf13/cobra"
+)
+
+func TestSetMaxProcs(t *testing.T) {
+	old := runtime.GOMAXPROCS(0)
+	defer runtime.GOMAXPROCS(old)
+
+	if got := setMaxProcs(1); got != 1 || runtime.GOMAXPROCS(0) != 1 {
+		t.Errorf("Expected GOMAXPROCS 1, got %d (effective %d)", runtime.GOMAXPROCS(0), got)
+	}
+
+	if got := setMaxProcs(3); got != 3 || runtime.GOMAXPROCS(0) != 3 {
+		t.Errorf("Expected GOMAXPROCS 3, got %d (effective %d)", runtime.GOMAXPROCS(0), got)
+	}
+
+	// Zero keeps the current setting
+	if got := setMaxProcs(0); got != 3 {
+		t.Errorf("Expected GOMAXPROCS to stay at 3, got %d", got)
+	}
+}
+
+func TestVersionJSON(t *testing.T) {
+	var buf bytes.Buffer
+	cmd := newVersionCmd()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("version --json failed: %v", err)
+	}
+
+	var info versionInfo
+	if err := json.Unmarshal(buf.Bytes(), &info); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if info.Version != picalc.VERSION {
+		t.Errorf("Expected version %s, got %s", picalc.VERSION, info.Version)
+	}
+	if info.Go != runtime.Version() {
+		t.Errorf("Expected Go version %s, got %s", runtime.Version(), info.Go)
+	}
+	if len(info.Algorithms) == 0 || info.Algorithms[0] != "chudnovsky" {
+		t.Errorf("Expected chudnovsky among the algorithms, got %v", info.Algorithms)
+	}
+}
+
+func TestRejectNonPositiveDigits(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	for _, tt := range []struct {
+		cmd  func() *cobra.Command
+		args []string
+	}{
+		{newImageCmd, []string{"--out", out, "--", "-5"}},
+		{newImageCmd, []string{"--out", out, "0"}},
+		{newAudioCmd, []string{"--out", out, "--", "-5"}},
+		{newAudioCmd, []string{"--out", out, "0"}},
+		{newLandmarksCmd, []string{"--", "-5"}},
+		{newGenGoCmd, []string{"--out", out, "--digits=-5"}},
+	} {
+		cmd := tt.cmd()
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(tt.args)
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "positive integer") {
+			t.Errorf("%s %v: expected a positive integer error, got %v", cmd.Name(), tt.args, err)
+		}
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("Expected no output file, got %v", err)
+	}
+}
+
+func TestProfiling(t *testing.T) {
+	dir := t.TempDir()
+	cpuPath := filepath.Join(dir, "cpu.prof")
+	memPath := filepath.Join(dir, "mem.prof")
+
+	stop, err := startProfiling(cpuPath, memPath)
+	if err != nil {
+		t.Fatalf("Failed to start profiling: %v", err)
+	}
+
+	pi := picalc.NewPi(2000)
+	picalc.CalculatePi(2000, pi)
+
+	if err := stop(); err != nil {
+		t.Fatalf("Failed to stop profiling: %v", err)
+	}
+
+	for _, path := range []string{cpuPath, memPath} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Errorf("Profile %s was not written: %v", path, err)
+		} else if info.Size() == 0 {
+			t.Errorf("Profile %s is empty", path)
+		}
+	}
+
+	// Profiling is off when no paths are given
+	stop, err = startProfiling("", "")
+	if err != nil || stop() != nil {
+		t.Errorf("Expected no-op profiling to succeed, got %v", err)
+	}
+}
+
+func TestWritePartial(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// A cancelled calculation, as after Ctrl-C
+	pi := picalc.NewPi(3000)
+	if err := picalc.CalculatePiContext(ctx, 3000, pi); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	path := partialPath(filepath.Join(t.TempDir(), "pi.txt"))
+	if err := writePartial(path, pi, time.Second); err != nil {
+		t.Fatalf("Failed to write partial results: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Partial results were not written: %v", err)
+	}
+	if !strings.Contains(string(content), "Interrupted after 1s") {
+		t.Errorf("Unexpected partial note: %s", content)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe to write from the progress goroutine
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// steppingSource completes another 10 of 100 terms each time it is polled
+type steppingSource struct {
+	completed atomic.Int64
+}
+
+func (s *steppingSource) GetTerms() (int64, int64) {
+	return s.completed.Add(10), 100
+}
+
+func (s *steppingSource) Fraction() float64 {
+	return float64(s.completed.Load()) / 100
+}
+
+func TestTextProgress(t *testing.T) {
+	var out syncBuffer
+
+	stop := runProgress(newTextRenderer(&out), 20*time.Millisecond, &steppingSource{})
+	time.Sleep(110 * time.Millisecond)
+	stop(true)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+
+	// About one line per interval, then the final line
+	updates := len(lines) - 1
+	if updates < 3 || updates > 6 {
+		t.Errorf("Expected about 5 progress lines at a 20ms interval over 110ms, got %d:\n%s", updates, out.String())
+	}
+	if !strings.HasPrefix(lines[0], "10% - 10/100 terms - ETA ") {
+		t.Errorf("Unexpected first line: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[len(lines)-1], "100% - done in ") {
+		t.Errorf("Unexpected final line: %q", lines[len(lines)-1])
+	}
+}
+
+func TestParsePrecisions(t *testing.T) {
+	precisions, err := parsePrecisions("10, 100,1000")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(precisions) != 3 || precisions[0] != 10 || precisions[2] != 1000 {
+		t.Errorf("Unexpected precisions: %v", precisions)
+	}
+
+	for _, bad := range []string{"", "10,abc", "10,0", "-5"} {
+		if _, err := parsePrecisions(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	var out bytes.Buffer
+	if err := runBatch(strings.NewReader("10\n20\n\nabc\n9223372036854775807\n10\n"), &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 JSON lines, got %d:\n%s", len(lines), out.String())
+	}
+
+	var first, second, repeated batchResult
+	json.Unmarshal([]byte(lines[0]), &first)
+	json.Unmarshal([]byte(lines[1]), &second)
+	json.Unmarshal([]byte(lines[4]), &repeated)
+
+	if first.Precision != 10 || first.Digits != "3.1415926535" {
+		t.Errorf("Unexpected first result: %+v", first)
+	}
+	if second.Precision != 20 || second.Digits != "3.14159265358979323846" {
+		t.Errorf("Unexpected second result: %+v", second)
+	}
+	if repeated != first {
+		t.Errorf("Expected the repeated precision to match the first result: %+v", repeated)
+	}
+
+	var bad batchError
+	if err := json.Unmarshal([]byte(lines[2]), &bad); err != nil || bad.Input != "abc" || bad.Error == "" {
+		t.Errorf("Expected an error object for the invalid line, got %s", lines[2])
+	}
+	var huge batchError
+	if err := json.Unmarshal([]byte(lines[3]), &huge); err != nil || huge.Input != "9223372036854775807" || huge.Error == "" {
+		t.Errorf("Expected an error object for the oversized precision, got %s", lines[3])
+	}
+}
+
+func TestBatchCacheEviction(t *testing.T) {
+	cache := &batchCache{results: make(map[int64]batchResult)}
+	third := batchCacheDigits / 3
+	for _, precision := range []int64{1, 2, 3, 4} {
+		cache.add(batchResult{Precision: precision, Digits: strings.Repeat("1", third)})
+	}
+
+	if _, ok := cache.results[1]; ok || len(cache.results) != 3 || cache.digits != 3*third {
+		t.Errorf("Expected the oldest result evicted, got %d results of %d digits", len(cache.results), cache.digits)
+	}
+
+	// A result larger than the budget is never cached
+	cache.add(batchResult{Precision: 5, Digits: strings.Repeat("1", batchCacheDigits+1)})
+	if _, ok := cache.results[5]; ok || len(cache.results) != 3 {
+		t.Errorf("Expected the oversized result not to be cached, got %d results", len(cache.results))
+	}
+}
+
+func TestFormatPreview(t *testing.T) {
+	digits, err := picalc.PiDigits(50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := formatPreview(digits, calculateOptions{preview: 20}); got != "3.14159265358979323846..." {
+		t.Errorf("Expected 20 fractional digits, got %q", got)
+	}
+	if got := formatPreview(digits, calculateOptions{preview: 0}); got != picalc.FormatGrouped(digits, 0, "") {
+		t.Errorf("Expected every digit with preview 0, got %q", got)
+	}
+	if got := formatPreview(digits, calculateOptions{preview: 50}); strings.HasSuffix(got, "...") {
+		t.Errorf("Expected no ellipsis when every digit fits, got %q", got)
+	}
+	if got := formatPreview(digits, calculateOptions{preview: 5, fractionalOnly: true}); got != "14159..." {
+		t.Errorf("Unexpected fractional preview %q", got)
+	}
+	if got := formatPreview(digits, calculateOptions{preview: 5, decimalSep: ','}); got != "3,14159..." {
+		t.Errorf("Expected a comma separator, got %q", got)
+	}
+}
+
+// fakeRenderer records the progress it is asked to show
+type fakeRenderer struct {
+	updates  atomic.Int64
+	finished atomic.Int64
+	last     atomic.Value // float64 fraction of the last update
+}
+
+func (r *fakeRenderer) Update(src progressSource) {
+	r.updates.Add(1)
+	r.last.Store(src.Fraction())
+}
+
+func (r *fakeRenderer) Finish() {
+	r.finished.Add(1)
+}
+
+func TestCalculatePiProgress(t *testing.T) {
+	r := &fakeRenderer{}
+	pi := picalc.NewPi(2000)
+	opts := calculateOptions{preview: 10}
+
+	if err := calculatePi(context.Background(), pi, 2000, opts, r, time.Millisecond); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if r.finished.Load() != 1 {
+		t.Errorf("Expected the renderer to be finished once, got %d", r.finished.Load())
+	}
+
+	// The progress goroutine has exited, so no more updates arrive
+	updates := r.updates.Load()
+	time.Sleep(20 * time.Millisecond)
+	if r.updates.Load() != updates {
+		t.Error("Expected no progress updates after the calculation returned")
+	}
+}
+
+func TestCalculatePiInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := &fakeRenderer{}
+	pi := picalc.NewPi(100000)
+	err := calculatePi(ctx, pi, 100000, calculateOptions{}, r, time.Millisecond)
+	if !errors.Is(err, errInterrupted) {
+		t.Fatalf("Expected errInterrupted, got %v", err)
+	}
+
+	// An interrupted calculation shows where it stopped rather than 100%
+	if r.finished.Load() != 0 {
+		t.Error("Expected an interrupted calculation not to finish the renderer")
+	}
+	if last, _ := r.last.Load().(float64); last >= 1 {
+		t.Errorf("Expected partial progress, got %v", last)
+	}
+}
+
+func TestCalculatePiTimeout(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "pi.txt")
+	opts := calculateOptions{timeout: time.Millisecond, outputFile: output}
+
+	r := &fakeRenderer{}
+	pi := picalc.NewPi(1000000)
+	start := time.Now()
+	err := calculatePi(context.Background(), pi, 1000000, opts, r, time.Millisecond)
+	if !errors.Is(err, errTimedOut) {
+		t.Fatalf("Expected errTimedOut, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the timeout to return promptly, took %v", elapsed)
+	}
+	if pi.IsComplete() {
+		t.Error("Expected the timed out calculation to be incomplete")
+	}
+
+	// The digits file is not written, but the partial note is
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("Expected no output file, got %v", err)
+	}
+	if _, err := os.Stat(partialPath(output)); err != nil {
+		t.Errorf("Expected partial results: %v", err)
+	}
+}
+
+func TestCheckOutputFile(t *testing.T) {
+	dir := t.TempDir()
+
+	err := checkOutputFile(dir)
+	if !errors.Is(err, picalc.ErrOutputIsDirectory) {
+		t.Fatalf("Expected ErrOutputIsDirectory, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "--output "+filepath.Join(dir, "pi.txt")) {
+		t.Errorf("Expected a filename suggestion, got %v", err)
+	}
+
+	if err := checkOutputFile(filepath.Join(dir, "pi.txt")); err != nil {
+		t.Errorf("Expected a writable path, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/shammianand/picalc/pkg/picalc"
+)
+
+// partialPath returns where an interrupted calculation's results are saved
+func partialPath(outputFile string) string {
+	return outputFile + ".partial"
+}
+
+// writePartial records how far an interrupted calculation got. The Chudnovsky
+// series yields no digits until every term is summed, so the file notes the
+// progress made rather than holding digits.
+func writePartial(path string, pi *picalc.Pi, elapsed time.Duration) error {
+	note := fmt.Sprintf("Interrupted after %v at %.1f%% of the series terms.\n"+
+		"No digits are available: the Chudnovsky series produces digits only once every term is summed.\n",
+		elapsed.Round(time.Millisecond), pi.GetProgress())
+
+	if err := os.WriteFile(path, []byte(note), 0644); err != nil {
+		return fmt.Errorf("error writing partial results: %v", err)
+	}
+	return nil
+}
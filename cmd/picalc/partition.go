@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/shammianand/picalc/pkg/picalc"
+	"github.com/spf13/cobra"
+)
+
+func newPartitionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "partition",
+		Short: "Print balanced series term ranges for computing π on several machines",
+		Long:  "Prints one [a, b) range of Chudnovsky series terms per line, tab separated, together covering every term needed for --digits.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			digits, _ := cmd.Flags().GetInt64("digits")
+			parts, _ := cmd.Flags().GetInt("parts")
+			if digits < 1 {
+				return fmt.Errorf("--digits must be a positive integer")
+			}
+			if parts < 1 {
+				return fmt.Errorf("--parts must be a positive integer")
+			}
+
+			for _, r := range picalc.PartitionTerms(digits, parts) {
+				fmt.Printf("%d\t%d\n", r[0], r[1])
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Int64("digits", 0, "Decimal digits the combined ranges must yield")
+	cmd.Flags().Int("parts", 1, "Number of ranges, e.g. one per machine")
+	return cmd
+}
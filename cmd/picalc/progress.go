@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+	"golang.org/x/term"
+)
+
+// defaultTextInterval is how often progress lines are printed when stdout
+// is not a terminal and --progress-interval is not set
+const defaultTextInterval = 5 * time.Second
+
+// barInterval is how often the progress bar is redrawn
+const barInterval = 100 * time.Millisecond
+
+// progressSource reports how far a calculation has got, as *picalc.Pi does
+type progressSource interface {
+	// GetTerms returns completed and total units of work
+	GetTerms() (completed, total int64)
+	// Fraction returns the fraction of the work completed, from 0 to 1
+	Fraction() float64
+}
+
+// progressRenderer displays the progress of a running calculation
+type progressRenderer interface {
+	// Update shows the progress of src
+	Update(src progressSource)
+	// Finish shows the calculation as done
+	Finish()
+}
+
+// barRenderer animates a progress bar in percent
+type barRenderer struct {
+	bar *progressbar.ProgressBar
+}
+
+func newBarRenderer() *barRenderer {
+	return &barRenderer{bar: progressbar.Default(100, "Computing")}
+}
+
+func (r *barRenderer) Update(src progressSource) {
+	r.bar.Set(int(src.Fraction() * 100))
+}
+
+func (r *barRenderer) Finish() {
+	r.bar.Finish()
+}
+
+// textRenderer prints a plain line per update, for logs and CI where an
+// animated bar is useless, e.g. "42% - 120000/285000 terms - ETA 3m0s"
+type textRenderer struct {
+	w     io.Writer
+	start time.Time
+}
+
+func newTextRenderer(w io.Writer) *textRenderer {
+	return &textRenderer{w: w, start: time.Now()}
+}
+
+func (r *textRenderer) Update(src progressSource) {
+	completed, total := src.GetTerms()
+	if total <= 0 {
+		fmt.Fprintln(r.w, "0% - starting")
+		return
+	}
+
+	fraction := src.Fraction()
+	eta := "unknown"
+	if fraction > 0 {
+		elapsed := time.Since(r.start)
+		remaining := time.Duration(float64(elapsed) * (1 - fraction) / fraction)
+		eta = remaining.Round(time.Second).String()
+	}
+	fmt.Fprintf(r.w, "%d%% - %d/%d terms - ETA %s\n", int(fraction*100), completed, total, eta)
+}
+
+func (r *textRenderer) Finish() {
+	fmt.Fprintf(r.w, "100%% - done in %v\n", time.Since(r.start).Round(time.Millisecond))
+}
+
+// newProgressRenderer picks the bar on a terminal unless an interval is
+// given, and plain text lines otherwise. It returns the renderer and how
+// often to update it.
+func newProgressRenderer(interval time.Duration) (progressRenderer, time.Duration) {
+	if interval <= 0 && term.IsTerminal(int(os.Stdout.Fd())) {
+		return newBarRenderer(), barInterval
+	}
+	if interval <= 0 {
+		interval = defaultTextInterval
+	}
+	return newTextRenderer(os.Stdout), interval
+}
+
+// runProgress updates r from src every interval until the returned stop
+// function is called. Stop waits for any update in flight, then finishes r if
+// the calculation completed or shows where it stopped otherwise.
+func runProgress(r progressRenderer, interval time.Duration, src progressSource) (stop func(completed bool)) {
+	quit := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-quit:
+				return
+			case <-ticker.C:
+				r.Update(src)
+			}
+		}
+	}()
+
+	return func(completed bool) {
+		close(quit)
+		<-exited
+		if completed {
+			r.Finish()
+		} else {
+			r.Update(src)
+		}
+	}
+}
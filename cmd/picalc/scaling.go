@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shammianand/picalc/pkg/picalc"
+	"github.com/spf13/cobra"
+)
+
+func newProfileCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "profile [precisions]",
+		Short: "Time π at several precisions and fit how the cost scales",
+		Long:  "Calculates π at each of a comma separated list of precisions, e.g. 10,100,1000,10000, and prints the time and memory of each along with the fitted exponent k in time ∝ digits^k.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			precisions, err := parsePrecisions(args[0])
+			if err != nil {
+				return err
+			}
+
+			points := picalc.ScalingProfile(precisions)
+
+			fmt.Printf("%12s  %14s  %12s\n", "digits", "time", "allocated")
+			for _, p := range points {
+				fmt.Printf("%12d  %14v  %9.2f MB\n", p.Precision, p.Duration, float64(p.Bytes)/(1024*1024))
+			}
+
+			if k := picalc.ScalingExponent(points); !math.IsNaN(k) {
+				fmt.Printf("Fitted scaling: time ∝ digits^%.2f\n", k)
+			}
+			return nil
+		},
+	}
+}
+
+func newThroughputCmd() *cobra.Command {
+	var throughputCmd = &cobra.Command{
+		Use:   "throughput",
+		Short: "Measure the digits per second this machine sustains",
+		Long:  "Calculates π at doubling precisions for a fixed wall-clock budget and reports the digits per second of the largest one that finished, for comparing hardware.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			seconds, _ := cmd.Flags().GetFloat64("seconds")
+			if seconds <= 0 {
+				return fmt.Errorf("--seconds must be positive, got %v", seconds)
+			}
+
+			budget := time.Duration(seconds * float64(time.Second))
+			fmt.Printf("Measuring throughput for %v...\n", budget)
+			fmt.Printf("Sustained throughput: %.0f digits/s\n", picalc.MeasureThroughput(budget))
+			return nil
+		},
+	}
+
+	throughputCmd.Flags().Float64("seconds", 10, "Wall-clock budget for the measurement")
+
+	return throughputCmd
+}
+
+// parsePrecisions parses a comma separated list of positive precisions
+func parsePrecisions(s string) ([]int64, error) {
+	var precisions []int64
+	for _, field := range strings.Split(s, ",") {
+		precision, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err != nil || precision < 1 {
+			return nil, fmt.Errorf("invalid precision %q: must be a positive integer", field)
+		}
+		precisions = append(precisions, precision)
+	}
+	return precisions, nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/shammianand/picalc/pkg/picalc"
+	"github.com/spf13/cobra"
+)
+
+func newSelfTestCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "selftest",
+		Short: "Run internal consistency checks",
+		Long:  "Checks that independent ways of computing π agree, to confirm a build works on this machine.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			failed := 0
+			for _, r := range picalc.RunSelfTest() {
+				status := "PASS"
+				if !r.Passed {
+					status = "FAIL"
+					failed++
+				}
+				fmt.Printf("%s  %s: %s\n", status, r.Name, r.Detail)
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d self-test checks failed", failed)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/shammianand/picalc/pkg/picalc"
+	"github.com/shammianand/picalc/pkg/server"
+	"github.com/spf13/cobra"
+)
+
+func newServeCmd() *cobra.Command {
+	var serveCmd = &cobra.Command{
+		Use:   "serve",
+		Short: "Serve π digits and metrics over HTTP",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addr, _ := cmd.Flags().GetString("addr")
+			maxDigits, _ := cmd.Flags().GetInt64("max-digits")
+
+			// Pay for the constants before the first request rather than during it
+			picalc.PrewarmConstants(maxDigits)
+
+			fmt.Printf("Serving π on %s (GET /pi?digits=N, GET /pi/stream?digits=N, GET /pi/hex?n=K, GET /metrics)\n", addr)
+			return http.ListenAndServe(addr, server.New(maxDigits))
+		},
+	}
+
+	serveCmd.Flags().String("addr", ":8080", "Address to listen on")
+	serveCmd.Flags().Int64("max-digits", server.DefaultMaxDigits, "Largest precision a request may ask for")
+
+	return serveCmd
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/shammianand/picalc/pkg/picalc"
+	"github.com/spf13/cobra"
+)
+
+func newVectorsCmd() *cobra.Command {
+	var vectorsCmd = &cobra.Command{
+		Use:   "vectors",
+		Short: "Write π at precisions 1..N as JSON test vectors",
+		Long:  "Writes a JSON object mapping each precision from 1 to --max to π truncated to that many decimal places, as golden test data for other π implementations.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			maxPrecision, _ := cmd.Flags().GetInt64("max")
+			out, _ := cmd.Flags().GetString("out")
+
+			if maxPrecision < 1 {
+				return fmt.Errorf("--max must be a positive integer, got %d", maxPrecision)
+			}
+
+			data, err := json.MarshalIndent(picalc.GenerateVectors(maxPrecision), "", "  ")
+			if err != nil {
+				return fmt.Errorf("error encoding vectors: %v", err)
+			}
+			if err := os.WriteFile(out, append(data, '\n'), 0644); err != nil {
+				return fmt.Errorf("error writing vectors: %v", err)
+			}
+
+			fmt.Printf("Wrote %d vectors to %s\n", maxPrecision, out)
+			return nil
+		},
+	}
+
+	vectorsCmd.Flags().Int64("max", 100, "Largest precision to generate")
+	vectorsCmd.Flags().String("out", "vectors.json", "File to write the vectors to")
+
+	return vectorsCmd
+}
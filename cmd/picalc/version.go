@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+
+	"github.com/shammianand/picalc/pkg/picalc"
+	"github.com/spf13/cobra"
+)
+
+// versionInfo is the machine-readable form of the version subcommand
+type versionInfo struct {
+	Version    string   `json:"version"`
+	Go         string   `json:"go"`
+	Algorithms []string `json:"algorithms"`
+}
+
+func newVersionCmd() *cobra.Command {
+	var versionCmd = &cobra.Command{
+		Use:   "version",
+		Short: "Print the version, Go version and available algorithms",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return writeVersion(cmd.OutOrStdout(), asJSON)
+		},
+	}
+
+	versionCmd.Flags().Bool("json", false, "Print version information as JSON")
+
+	return versionCmd
+}
+
+// writeVersion writes the version information to w, as JSON if asJSON is set
+func writeVersion(w io.Writer, asJSON bool) error {
+	info := versionInfo{
+		Version:    picalc.VERSION,
+		Go:         runtime.Version(),
+		Algorithms: picalc.Algorithms(),
+	}
+
+	if asJSON {
+		return json.NewEncoder(w).Encode(info)
+	}
+
+	_, err := fmt.Fprintf(w, "picalc %s (%s)\nalgorithms: %v\n", info.Version, info.Go, info.Algorithms)
+	return err
+}
+
+func newCompletionCmd(rootCmd *cobra.Command) *cobra.Command {
+	return &cobra.Command{
+		Use:       "completion [bash|zsh|fish]",
+		Short:     "Generate a shell completion script",
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return rootCmd.GenBashCompletionV2(out, true)
+			case "zsh":
+				return rootCmd.GenZshCompletion(out)
+			default:
+				return rootCmd.GenFishCompletion(out, true)
+			}
+		},
+	}
+}
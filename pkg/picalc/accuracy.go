@@ -0,0 +1,24 @@
+package picalc
+
+import "math"
+
+// minRelativeError is the smallest normal float64, about 2.2e-308
+const minRelativeError = 0x1p-1022
+
+// DigitsForAccuracy returns how many decimal places of Pi keep its relative
+// error within relativeError, with a digit to spare for rounding in the
+// caller's own arithmetic. Errors of 1 or more need a single digit, and
+// smaller errors than a float64 can represent normally, including 0, negative
+// errors and NaN, are clamped to about 2.2e-308.
+func DigitsForAccuracy(relativeError float64) int64 {
+	if relativeError >= 1 {
+		return 1
+	}
+	if !(relativeError >= minRelativeError) {
+		relativeError = minRelativeError
+	}
+
+	// Truncating to d places leaves an absolute error below 10^-d, so a
+	// relative error below 10^-d / 3
+	return int64(math.Ceil(-math.Log10(relativeError))) + 1
+}
@@ -0,0 +1,44 @@
+package picalc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDigitsForAccuracy(t *testing.T) {
+	if d := DigitsForAccuracy(1e-15); d < 16 {
+		t.Errorf("Expected at least 16 digits for 1e-15, got %d", d)
+	}
+
+	tests := []struct {
+		relativeError float64
+		expected      int64
+	}{
+		{1e-15, 16},
+		{1e-3, 4},
+		{0.5, 2},
+		{1, 1},
+		{10, 1},
+		{0, 309},
+		{-1, 309},
+		{1e-320, 309},
+		{math.NaN(), 309},
+	}
+	for _, tt := range tests {
+		if d := DigitsForAccuracy(tt.relativeError); d != tt.expected {
+			t.Errorf("DigitsForAccuracy(%v): expected %d, got %d", tt.relativeError, tt.expected, d)
+		}
+	}
+
+	// The digits really do meet the accuracy
+	for _, relErr := range []float64{1e-3, 1e-8, 1e-12} {
+		d := DigitsForAccuracy(relErr)
+		pi := NewPi(d)
+		CalculatePi(d, pi)
+		scaled, _ := pi.ScaledInt(int(d))
+		approx := float64(scaled.Int64()) / math.Pow(10, float64(d))
+		if got := math.Abs(approx-math.Pi) / math.Pi; got > relErr {
+			t.Errorf("%d digits give relative error %v, more than %v", d, got, relErr)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package picalc
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// CalculateApery calculates Apéry's constant ζ(3) = 1.2020569... to precision
+// decimal places, returning the integer digit followed by the fractional
+// digits, e.g. 1, 2, 0, 2, 0, 5, 6, 9. The digits are truncated.
+//
+// It sums the Amdahl–Zeilberger series
+//
+//	ζ(3) = 1/64 Σ (-1)^k (k!)^10 (205k² + 250k + 77) / ((2k+1)!)^5
+//
+// with the same binary splitting as the Chudnovsky series. Each term adds
+// about 3 digits, so it is much slower than Pi for the same precision.
+func CalculateApery(precision int64) ([]int, error) {
+	if precision < 0 {
+		return nil, fmt.Errorf("precision must be non-negative, got %d", precision)
+	}
+
+	const guard = 10
+	digits := precision + guard
+
+	// Each term gives log10(1024) ~ 3.01 digits
+	terms := int64(float64(digits)/3.01) + 2
+	_, Q, R := binarySplitApery(0, terms)
+
+	floatPrec := floatPrecision(digits)
+
+	// ζ(3) = R / (64 Q)
+	num := new(big.Float).SetPrec(floatPrec).SetInt(R)
+	den := new(big.Float).SetPrec(floatPrec).SetInt(Q)
+	den.Mul(den, big.NewFloat(64))
+	zeta := new(big.Float).SetPrec(floatPrec).Quo(num, den)
+
+	decimalStr := roundDecimal(zeta.Text('f', int(digits)), int(precision), RoundDown)
+
+	result := make([]int, 0, precision+1)
+	for i := 0; i < len(decimalStr); i++ {
+		if decimalStr[i] != '.' {
+			result = append(result, int(decimalStr[i]-'0'))
+		}
+	}
+	return result, nil
+}
+
+// binarySplitApery computes the Amdahl–Zeilberger series over [a, b) using
+// binary splitting. Term k is term k-1 times -k^5 / (32 (2k+1)^5), weighted
+// by 205k² + 250k + 77.
+func binarySplitApery(a, b int64) (*big.Int, *big.Int, *big.Int) {
+	if a == b {
+		return big.NewInt(1), big.NewInt(1), big.NewInt(0)
+	}
+
+	if b-a == 1 {
+		weight := big.NewInt(205*a*a + 250*a + 77)
+		if a == 0 {
+			return big.NewInt(1), big.NewInt(1), weight
+		}
+
+		// P(a) = a^5
+		k := big.NewInt(a)
+		P := new(big.Int).Exp(k, big.NewInt(5), nil)
+
+		// Q(a) = 32 (2a+1)^5
+		Q := new(big.Int).Exp(big.NewInt(2*a+1), big.NewInt(5), nil)
+		Q.Lsh(Q, 5)
+
+		// R(a) = (-1)^a P(a) (205a² + 250a + 77)
+		R := new(big.Int).Mul(P, weight)
+		if a%2 == 1 {
+			R.Neg(R)
+		}
+
+		return P, Q, R
+	}
+
+	m := (a + b) / 2
+	P1, Q1, R1 := binarySplitApery(a, m)
+	P2, Q2, R2 := binarySplitApery(m, b)
+
+	return combinePQR(P1, Q1, R1, P2, Q2, R2)
+}
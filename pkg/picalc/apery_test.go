@@ -0,0 +1,41 @@
+package picalc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCalculateApery(t *testing.T) {
+	// ζ(3) to 50 places
+	const expected = "1.20205690315959428539973816151144999076498629234049"
+
+	digits, err := CalculateApery(50)
+	if err != nil {
+		t.Fatalf("CalculateApery failed: %v", err)
+	}
+	if len(digits) != 51 {
+		t.Fatalf("Expected 51 digits, got %d", len(digits))
+	}
+
+	got := FormatGrouped(digits, 0, "")
+	if got != expected {
+		t.Errorf("Apéry digits mismatch.\nExpected: %s\nGot: %s", expected, got)
+	}
+
+	// Larger precisions agree on the shared prefix
+	more, err := CalculateApery(500)
+	if err != nil {
+		t.Fatalf("CalculateApery failed: %v", err)
+	}
+	if !strings.HasPrefix(FormatGrouped(more, 0, ""), expected) {
+		t.Error("500 digits do not extend the 50 digit result")
+	}
+
+	if digits, _ := CalculateApery(0); !reflect.DeepEqual(digits, []int{1}) {
+		t.Errorf("Expected [1] for precision 0, got %v", digits)
+	}
+	if _, err := CalculateApery(-1); err == nil {
+		t.Error("Expected an error for a negative precision")
+	}
+}
@@ -0,0 +1,112 @@
+package picalc
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"runtime"
+	"sync"
+)
+
+const hexChars = "0123456789ABCDEF"
+
+// NthHexDigit returns the hex digit of Pi at position n after the point
+// (position 0 is the "2" in 3.243F6A88...) using the Bailey–Borwein–Plouffe
+// formula, without computing any of the preceding digits
+func NthHexDigit(n int64) (byte, error) {
+	if n < 0 {
+		return 0, fmt.Errorf("hex digit position must be non-negative, got %d", n)
+	}
+
+	// 16^n * Pi = 4*S(1) - 2*S(4) - S(5) - S(6), keeping only the fractional part
+	x := 4*bbpSeries(1, n) - 2*bbpSeries(4, n) - bbpSeries(5, n) - bbpSeries(6, n)
+	x -= math.Floor(x)
+
+	return hexChars[int(x*16)], nil
+}
+
+// HexDigits returns count hex digits of Pi starting at position start, computing
+// each position independently with BBP across a pool of workers. A workers value
+// of 0 or less uses GOMAXPROCS.
+func HexDigits(start, count int64, workers int) ([]byte, error) {
+	if start < 0 {
+		return nil, fmt.Errorf("hex digit position must be non-negative, got %d", start)
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("hex digit count must be non-negative, got %d", count)
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if int64(workers) > count {
+		workers = int(count)
+	}
+
+	result := make([]byte, count)
+	var wg sync.WaitGroup
+
+	// Each worker takes every workers-th position, so no coordination is needed
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := int64(w); i < count; i += int64(workers) {
+				// Positions are validated above, so this cannot fail
+				result[i], _ = NthHexDigit(start + i)
+			}
+		}(w)
+	}
+
+	wg.Wait()
+	return result, nil
+}
+
+// bbpSeries returns the fractional part of sum_k 16^(n-k) / (8k+j)
+func bbpSeries(j, n int64) float64 {
+	s := 0.0
+
+	// Left sum, using modular exponentiation to keep terms small
+	for k := int64(0); k <= n; k++ {
+		d := uint64(8*k + j)
+		s += float64(modPow16(uint64(n-k), d)) / float64(d)
+		s -= math.Floor(s)
+	}
+
+	// Right tail, until terms no longer affect a float64
+	for k := n + 1; ; k++ {
+		t := math.Pow(16, float64(n-k)) / float64(8*k+j)
+		if t < 1e-17 {
+			break
+		}
+		s += t
+		s -= math.Floor(s)
+	}
+
+	return s
+}
+
+// modPow16 returns 16^e mod m without overflowing for any 64-bit modulus
+func modPow16(e, m uint64) uint64 {
+	if m == 1 {
+		return 0
+	}
+
+	result := uint64(1)
+	base := uint64(16) % m
+	for e > 0 {
+		if e&1 == 1 {
+			result = mulMod(result, base, m)
+		}
+		base = mulMod(base, base, m)
+		e >>= 1
+	}
+
+	return result
+}
+
+// mulMod returns a*b mod m using a 128-bit intermediate product
+func mulMod(a, b, m uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	_, rem := bits.Div64(hi%m, lo, m)
+	return rem
+}
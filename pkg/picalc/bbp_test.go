@@ -0,0 +1,56 @@
+package picalc
+
+import "testing"
+
+func TestNthHexDigit(t *testing.T) {
+	expected := "243F6A8885A308D3"
+
+	for i := range expected {
+		d, err := NthHexDigit(int64(i))
+		if err != nil {
+			t.Fatalf("Unexpected error at position %d: %v", i, err)
+		}
+		if d != expected[i] {
+			t.Errorf("Hex digit %d mismatch. Expected: %c, Got: %c", i, expected[i], d)
+		}
+	}
+
+	if _, err := NthHexDigit(-1); err == nil {
+		t.Error("Expected an error for a negative position")
+	}
+}
+
+func TestHexDigits(t *testing.T) {
+	digits, err := HexDigits(0, 16, 4)
+	if err != nil {
+		t.Fatalf("Failed to compute hex digits: %v", err)
+	}
+	if string(digits) != "243F6A8885A308D3" {
+		t.Errorf("First 16 hex digits mismatch.\nExpected: 243F6A8885A308D3\nGot: %s", digits)
+	}
+
+	// An offset block agrees with the same positions from the start
+	block, err := HexDigits(8, 8, 0)
+	if err != nil {
+		t.Fatalf("Failed to compute hex digits: %v", err)
+	}
+	if string(block) != "85A308D3" {
+		t.Errorf("Offset block mismatch.\nExpected: 85A308D3\nGot: %s", block)
+	}
+
+	// Deep positions don't depend on the earlier ones
+	deep, err := HexDigits(1000, 8, 3)
+	if err != nil {
+		t.Fatalf("Failed to compute hex digits: %v", err)
+	}
+	if string(deep) != "49F1C09B" {
+		t.Errorf("Hex digits from position 1000 mismatch.\nExpected: 49F1C09B\nGot: %s", deep)
+	}
+
+	if _, err := HexDigits(-1, 4, 1); err == nil {
+		t.Error("Expected an error for a negative start")
+	}
+	if empty, err := HexDigits(0, 0, 4); err != nil || len(empty) != 0 {
+		t.Errorf("Expected no digits for a zero count, got %q (%v)", empty, err)
+	}
+}
@@ -0,0 +1,232 @@
+package picalc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// binaryStateVersion is the format version written by MarshalBinary
+const binaryStateVersion = 1
+
+// ErrInvalidState is returned when UnmarshalBinary is given malformed data
+var ErrInvalidState = errors.New("invalid pi state")
+
+// MarshalBinary encodes the precision, progress, options, and digits of p.
+// Digits are packed two per byte. It implements encoding.BinaryMarshaler.
+func (p *Pi) MarshalBinary() ([]byte, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	buf := make([]byte, 0, 1+5*binary.MaxVarintLen64+(len(p.digits)+1)/2)
+	buf = append(buf, binaryStateVersion)
+	buf = binary.AppendUvarint(buf, uint64(p.precision))
+	buf = binary.AppendUvarint(buf, uint64(p.computed.Load()))
+	buf = binary.AppendUvarint(buf, uint64(p.totalTerms.Load()))
+	buf = binary.AppendUvarint(buf, uint64(p.opts.Rounding))
+	buf = binary.AppendUvarint(buf, uint64(len(p.digits)))
+	buf = append(buf, packDigits(p.digits)...)
+
+	return buf, nil
+}
+
+// UnmarshalBinary restores state written by MarshalBinary into p.
+// It implements encoding.BinaryUnmarshaler.
+func (p *Pi) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != binaryStateVersion {
+		return fmt.Errorf("%w: unsupported version", ErrInvalidState)
+	}
+	data = data[1:]
+
+	var fields [5]uint64
+	for i := range fields {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("%w: truncated header", ErrInvalidState)
+		}
+		fields[i] = v
+		data = data[n:]
+	}
+	precision, computed, totalTerms, rounding, count := fields[0], fields[1], fields[2], fields[3], fields[4]
+	if computed > totalTerms {
+		return fmt.Errorf("%w: %d of %d terms computed", ErrInvalidState, computed, totalTerms)
+	}
+	if precision > math.MaxInt64 || totalTerms > math.MaxInt64 {
+		return fmt.Errorf("%w: precision %d or %d terms out of range", ErrInvalidState, precision, totalTerms)
+	}
+
+	n, err := packedCount(count, data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+
+	digits, err := unpackDigits(data, n)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+
+	p.mutex.Lock()
+	p.digits = digits
+	p.precision = int64(precision)
+	p.opts.Rounding = RoundingMode(rounding)
+	p.mutex.Unlock()
+
+	p.totalTerms.Store(int64(totalTerms))
+	p.computed.Store(int64(computed))
+	p.complete.Store(totalTerms > 0 && computed >= totalTerms)
+
+	return nil
+}
+
+// digitsFileMagic starts every file written by WriteDigitsBinary
+const digitsFileMagic = "PIDG"
+
+// digitsFileVersion is the format version written by WriteDigitsBinary
+const digitsFileVersion = 1
+
+// digitsHeaderSize is the magic, version byte, and 64-bit digit count
+const digitsHeaderSize = len(digitsFileMagic) + 1 + 8
+
+// ErrInvalidDigitsFile is returned when ReadDigitsBinary is given a malformed file
+var ErrInvalidDigitsFile = errors.New("invalid binary digits file")
+
+// WriteDigitsBinary writes digits to filename packed two per byte after a
+// header holding a magic number, the format version, and the digit count
+// (precision + 1). The file is about half the size of the text form and is
+// written atomically like WriteDigitsToFile.
+func WriteDigitsBinary(digits []int, filename string) error {
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		header := make([]byte, 0, digitsHeaderSize)
+		header = append(header, digitsFileMagic...)
+		header = append(header, digitsFileVersion)
+		header = binary.BigEndian.AppendUint64(header, uint64(len(digits)))
+
+		if _, err := w.Write(header); err != nil {
+			return err
+		}
+		_, err := w.Write(packDigits(digits))
+		return err
+	})
+}
+
+// ReadDigitsBinary reads digits written by WriteDigitsBinary, gzip compressed or not
+func ReadDigitsBinary(filename string) ([]int, error) {
+	data, err := readDigitFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %v", err)
+	}
+	return decodeDigitsBinary(data)
+}
+
+// isDigitsBinary reports whether data starts like a WriteDigitsBinary file
+func isDigitsBinary(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(digitsFileMagic))
+}
+
+// decodeDigitsBinary decodes the contents of a WriteDigitsBinary file
+func decodeDigitsBinary(data []byte) ([]int, error) {
+	if len(data) < digitsHeaderSize || !isDigitsBinary(data) {
+		return nil, fmt.Errorf("%w: bad magic", ErrInvalidDigitsFile)
+	}
+	if version := data[len(digitsFileMagic)]; version != digitsFileVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidDigitsFile, version)
+	}
+
+	count := binary.BigEndian.Uint64(data[len(digitsFileMagic)+1:])
+	data = data[digitsHeaderSize:]
+	n, err := packedCount(count, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDigitsFile, err)
+	}
+
+	digits, err := unpackDigits(data, n)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDigitsFile, err)
+	}
+	return digits, nil
+}
+
+// matchDigitsBinary streams a WriteDigitsBinary file from r, comparing its
+// digits against digits. It returns how many match, stopping at the end of
+// either, or the position of the first divergent digit along with an error.
+func matchDigitsBinary(r io.Reader, digits []int) (int, error) {
+	header := make([]byte, digitsHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil || !isDigitsBinary(header) {
+		return 0, fmt.Errorf("%w: bad magic", ErrInvalidDigitsFile)
+	}
+	if version := header[len(digitsFileMagic)]; version != digitsFileVersion {
+		return 0, fmt.Errorf("%w: unsupported version %d", ErrInvalidDigitsFile, version)
+	}
+	count := binary.BigEndian.Uint64(header[len(digitsFileMagic)+1:])
+
+	var packed [1]byte
+	i := 0
+	for ; uint64(i) < count && i < len(digits); i++ {
+		d := packed[0] & 0x0f
+		if i%2 == 0 {
+			if _, err := io.ReadFull(r, packed[:]); err != nil {
+				return i, fmt.Errorf("%w: need %d bytes for %d digits: %v", ErrInvalidDigitsFile, (count+1)/2, count, err)
+			}
+			d = packed[0] >> 4
+		}
+		if d > 9 {
+			return i, fmt.Errorf("%w: invalid digit %d at position %d", ErrInvalidDigitsFile, d, i)
+		}
+		if digits[i] != int(d) {
+			return i, fmt.Errorf("digit %d mismatch: expected %d, got %d", i, d, digits[i])
+		}
+	}
+	return i, nil
+}
+
+// packDigits packs decimal digits two per byte, high nibble first
+func packDigits(digits []int) []byte {
+	packed := make([]byte, (len(digits)+1)/2)
+	for i, d := range digits {
+		if i%2 == 0 {
+			packed[i/2] = byte(d) << 4
+		} else {
+			packed[i/2] |= byte(d)
+		}
+	}
+	return packed
+}
+
+// packedCount checks a digit count read from an untrusted header against
+// the packed bytes that follow it and returns it as an int. The count is
+// bounded by the payload before any arithmetic, so a huge count can't wrap
+// around the size check or reach make.
+func packedCount(count uint64, packed []byte) (int, error) {
+	if count > 2*uint64(len(packed)) || count > math.MaxInt {
+		return 0, fmt.Errorf("%d digits can't fit in %d bytes", count, len(packed))
+	}
+	if uint64(len(packed)) != (count+1)/2 {
+		return 0, fmt.Errorf("expected %d digit bytes, got %d", (count+1)/2, len(packed))
+	}
+	return int(count), nil
+}
+
+// unpackDigits reverses packDigits, returning count digits
+func unpackDigits(packed []byte, count int) ([]int, error) {
+	if len(packed) < (count+1)/2 {
+		return nil, fmt.Errorf("need %d bytes for %d digits, got %d", (count+1)/2, count, len(packed))
+	}
+
+	digits := make([]int, count)
+	for i := range digits {
+		b := packed[i/2]
+		if i%2 == 0 {
+			b >>= 4
+		} else {
+			b &= 0x0f
+		}
+		if b > 9 {
+			return nil, fmt.Errorf("invalid digit %d at position %d", b, i)
+		}
+		digits[i] = int(b)
+	}
+	return digits, nil
+}
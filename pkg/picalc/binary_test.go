@@ -0,0 +1,120 @@
+package picalc
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Pi must satisfy the standard binary encoding interfaces
+var (
+	_ encoding.BinaryMarshaler   = (*Pi)(nil)
+	_ encoding.BinaryUnmarshaler = (*Pi)(nil)
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	for _, precision := range []int64{10, 101} {
+		pi := NewPi(precision)
+		CalculatePi(precision, pi)
+
+		data, err := pi.MarshalBinary()
+		if err != nil {
+			t.Fatalf("Failed to marshal: %v", err)
+		}
+
+		var restored Pi
+		if err := restored.UnmarshalBinary(data); err != nil {
+			t.Fatalf("Failed to unmarshal: %v", err)
+		}
+
+		n := int(precision) + 1
+		if !reflect.DeepEqual(restored.GetDigits(n), pi.GetDigits(n)) {
+			t.Errorf("Digits differ after round trip.\nExpected: %v\nGot: %v", pi.GetDigits(n), restored.GetDigits(n))
+		}
+		if restored.precision != pi.precision || restored.GetProgress() != pi.GetProgress() {
+			t.Errorf("State differs after round trip: precision %d vs %d, progress %f vs %f",
+				restored.precision, pi.precision, restored.GetProgress(), pi.GetProgress())
+		}
+	}
+}
+
+func TestBinaryRejectsInvalid(t *testing.T) {
+	pi := NewPi(20)
+	CalculatePi(20, pi)
+	data, _ := pi.MarshalBinary()
+
+	// A header of precision, computed, total terms, rounding and digit count
+	header := func(fields ...uint64) []byte {
+		buf := []byte{binaryStateVersion}
+		for _, f := range fields {
+			buf = binary.AppendUvarint(buf, f)
+		}
+		return buf
+	}
+
+	var restored Pi
+	for name, bad := range map[string][]byte{
+		"Empty":     nil,
+		"Version":   append([]byte{99}, data[1:]...),
+		"Truncated": data[:len(data)-1],
+		// (count+1)/2 wraps around to 0, matching an empty payload
+		"HugeCount":     header(20, 1, 1, 0, ^uint64(0)),
+		"CountTooLarge": append(header(20, 1, 1, 0, 1<<40), 0x31, 0x41),
+		"ComputedAhead": append(header(2, 2, 1, 0, 3), 0x31, 0x40),
+	} {
+		if err := restored.UnmarshalBinary(bad); !errors.Is(err, ErrInvalidState) {
+			t.Errorf("%s: expected ErrInvalidState, got %v", name, err)
+		}
+	}
+}
+
+func TestDigitsBinaryFile(t *testing.T) {
+	pi := NewPi(101)
+	CalculatePi(101, pi)
+	digits := pi.GetDigits(102)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pi.bin")
+	if err := WriteDigitsBinary(digits, path); err != nil {
+		t.Fatalf("Failed to write binary file: %v", err)
+	}
+
+	restored, err := ReadDigitsBinary(path)
+	if err != nil {
+		t.Fatalf("Failed to read binary file: %v", err)
+	}
+	if !reflect.DeepEqual(restored, digits) {
+		t.Errorf("Digits differ after round trip.\nExpected: %v\nGot: %v", digits, restored)
+	}
+
+	// Roughly half the size of the text form
+	info, _ := os.Stat(path)
+	if expected := int64(digitsHeaderSize + 51); info.Size() != expected {
+		t.Errorf("Expected %d bytes, got %d", expected, info.Size())
+	}
+
+	// Verification accepts the binary form
+	if err := VerifyDigitsFile(digits, path); err != nil {
+		t.Errorf("Verification of the binary file failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	for name, bad := range map[string][]byte{
+		"Empty":     nil,
+		"Magic":     append([]byte("NOPE"), data[4:]...),
+		"Version":   append(append([]byte(digitsFileMagic), 99), data[5:]...),
+		"Truncated": data[:len(data)-1],
+		// (count+1)/2 wraps around to 0, matching an empty payload
+		"HugeCount": binary.BigEndian.AppendUint64(append([]byte(digitsFileMagic), digitsFileVersion), ^uint64(0)),
+	} {
+		badPath := filepath.Join(dir, name+".bin")
+		os.WriteFile(badPath, bad, 0644)
+		if _, err := ReadDigitsBinary(badPath); !errors.Is(err, ErrInvalidDigitsFile) {
+			t.Errorf("%s: expected ErrInvalidDigitsFile, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package picalc
+
+import (
+	"math"
+	"math/big"
+	"sync"
+)
+
+// floatSqrt computes sqrt(10005) for the final step. Tests swap it to count calls.
+var floatSqrt = func(z, x *big.Float) *big.Float {
+	return z.Sqrt(x)
+}
+
+// sqrtCache holds sqrt(10005) as computed by PrewarmConstants
+var sqrtCache struct {
+	mutex sync.Mutex
+	value *big.Float
+}
+
+// BitsForDigits returns the number of bits needed to hold n decimal digits,
+// ceil(n * log2(10)), e.g. 333 for 100 digits
+func BitsForDigits(n int64) int64 {
+	return int64(math.Ceil(math.Log2(10) * float64(n)))
+}
+
+// floatPrecision returns the big.Float precision in bits used for digits
+// decimal digits, with 100 bits to spare for rounding
+func floatPrecision(digits int64) uint {
+	return uint(BitsForDigits(digits) + 100)
+}
+
+// PrewarmConstants computes and caches sqrt(10005) at the precision needed
+// for maxPrecision digits, so calculations up to maxPrecision (including their
+// verification run) skip the square root. Servers call it at startup so the
+// first request doesn't pay for it. The serial cutoff for binary splitting is
+// a fixed range of 100 terms and needs no warming.
+func PrewarmConstants(maxPrecision int64) {
+	prec := floatPrecision(maxPrecision + initialGuardDigits + 10)
+
+	sqrtCache.mutex.Lock()
+	defer sqrtCache.mutex.Unlock()
+
+	if sqrtCache.value != nil && sqrtCache.value.Prec() >= prec {
+		return
+	}
+	x := new(big.Float).SetPrec(prec).SetInt64(10005)
+	sqrtCache.value = floatSqrt(new(big.Float).SetPrec(prec), x)
+}
+
+// sqrt10005 returns sqrt(10005) at prec bits, from the cache if it is precise enough
+func sqrt10005(prec uint) *big.Float {
+	sqrtCache.mutex.Lock()
+	cached := sqrtCache.value
+	sqrtCache.mutex.Unlock()
+
+	if cached != nil && cached.Prec() >= prec {
+		return new(big.Float).SetPrec(prec).Set(cached)
+	}
+
+	x := new(big.Float).SetPrec(prec).SetInt64(10005)
+	return floatSqrt(new(big.Float).SetPrec(prec), x)
+}
@@ -0,0 +1,26 @@
+package picalc
+
+import (
+	"context"
+	"fmt"
+)
+
+// RunContinuous calculates Pi to step digits, then 2*step, 3*step and so on
+// until ctx is cancelled, calling onMilestone with each completed Pi so a
+// long-running service can save or checkpoint it. Each milestone is a fresh
+// calculation, as a finished Pi can't be extended, so later milestones take
+// longer. It returns ctx.Err() once cancelled, or the first calculation
+// error.
+func RunContinuous(ctx context.Context, step int64, onMilestone func(*Pi)) error {
+	if step <= 0 {
+		return fmt.Errorf("step must be positive, got %d", step)
+	}
+
+	for precision := step; ; precision += step {
+		pi := NewPi(precision)
+		if err := CalculatePiContext(ctx, precision, pi); err != nil {
+			return err
+		}
+		onMilestone(pi)
+	}
+}
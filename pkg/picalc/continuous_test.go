@@ -0,0 +1,33 @@
+package picalc
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRunContinuous(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var precisions []int64
+	err := RunContinuous(ctx, 50, func(pi *Pi) {
+		if _, err := pi.ValidateAgainstReference(int(pi.precision) + 1); err != nil {
+			t.Errorf("Milestone %d failed validation: %v", pi.precision, err)
+		}
+		if precisions = append(precisions, pi.precision); len(precisions) == 3 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	if len(precisions) != 3 || precisions[0] != 50 || precisions[1] != 100 || precisions[2] != 150 {
+		t.Errorf("Expected milestones at 50, 100 and 150 digits, got %v", precisions)
+	}
+
+	if err := RunContinuous(context.Background(), 0, func(*Pi) {}); err == nil {
+		t.Error("Expected an error for a zero step")
+	}
+}
@@ -0,0 +1,151 @@
+package picalc
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// splitFormatVersion is the format version written by BinarySplitRange
+const splitFormatVersion = 1
+
+// ErrInvalidSplit is returned when CombineSerialized is given malformed or
+// non-contiguous ranges
+var ErrInvalidSplit = errors.New("invalid split range")
+
+// splitRange is a decoded BinarySplitRange result
+type splitRange struct {
+	a, b    int64
+	P, Q, R *big.Int
+}
+
+// BinarySplitRange computes the Chudnovsky series terms [a, b) and serializes
+// the resulting P, Q and R, so ranges can be computed on separate machines and
+// merged with CombineSerialized.
+func BinarySplitRange(a, b int64) ([]byte, error) {
+	if a < 0 {
+		return nil, fmt.Errorf("binary split: negative term index %d", a)
+	}
+
+	A, B, C3_24 := chudnovskyConstants()
+	P, Q, R, err := binarySplitParallel(context.Background(), a, b, A, B, C3_24, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := []byte{splitFormatVersion}
+	buf = binary.AppendUvarint(buf, uint64(a))
+	buf = binary.AppendUvarint(buf, uint64(b))
+	for _, x := range []*big.Int{P, Q, R} {
+		enc, err := x.GobEncode()
+		if err != nil {
+			return nil, fmt.Errorf("error encoding split range: %w", err)
+		}
+		buf = binary.AppendUvarint(buf, uint64(len(enc)))
+		buf = append(buf, enc...)
+	}
+
+	return buf, nil
+}
+
+// PartitionTerms splits the Chudnovsky series terms needed for precision
+// digits, with the guard digits CalculatePi starts with, into parts ranges
+// [a, b) for BinarySplitRange. The ranges cover every term exactly once, in
+// order, and differ in size by at most one term. A parts below 1 is treated
+// as 1, and no more ranges than terms are returned.
+func PartitionTerms(precision int64, parts int) [][2]int64 {
+	terms := chudnovskyTerms(max(precision, 0) + initialGuardDigits)
+	n := min(int64(max(parts, 1)), terms)
+
+	ranges := make([][2]int64, n)
+	var a int64
+	for i := range ranges {
+		b := a + terms/n
+		if int64(i) < terms%n {
+			b++
+		}
+		ranges[i] = [2]int64{a, b}
+		a = b
+	}
+	return ranges
+}
+
+// CombineSerialized merges ranges from BinarySplitRange into Q and R for the
+// whole series, from which Pi = 426880 * sqrt(10005) * Q / R. The ranges may
+// be given in any order but must be contiguous and start at term 0.
+func CombineSerialized(parts [][]byte) (*big.Int, *big.Int, error) {
+	if len(parts) == 0 {
+		return nil, nil, fmt.Errorf("%w: no ranges to combine", ErrInvalidSplit)
+	}
+
+	ranges := make([]splitRange, len(parts))
+	for i, data := range parts {
+		r, err := decodeSplitRange(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("range %d: %w", i, err)
+		}
+		ranges[i] = r
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].a < ranges[j].a })
+	if ranges[0].a != 0 {
+		return nil, nil, fmt.Errorf("%w: first range starts at term %d, not 0", ErrInvalidSplit, ranges[0].a)
+	}
+
+	P, Q, R := ranges[0].P, ranges[0].Q, ranges[0].R
+	for i := 1; i < len(ranges); i++ {
+		if prev, next := ranges[i-1], ranges[i]; prev.b != next.a {
+			return nil, nil, fmt.Errorf("%w: range [%d, %d) does not follow [%d, %d)", ErrInvalidSplit, next.a, next.b, prev.a, prev.b)
+		}
+		P, Q, R = combinePQR(P, Q, R, ranges[i].P, ranges[i].Q, ranges[i].R)
+	}
+
+	return Q, R, nil
+}
+
+// decodeSplitRange reverses the encoding done by BinarySplitRange
+func decodeSplitRange(data []byte) (splitRange, error) {
+	var r splitRange
+	if len(data) == 0 || data[0] != splitFormatVersion {
+		return r, fmt.Errorf("%w: unsupported version", ErrInvalidSplit)
+	}
+	data = data[1:]
+
+	var bounds [2]uint64
+	for i := range bounds {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return r, fmt.Errorf("%w: truncated header", ErrInvalidSplit)
+		}
+		bounds[i] = v
+		data = data[n:]
+	}
+	r.a, r.b = int64(bounds[0]), int64(bounds[1])
+	if r.a < 0 || r.b < r.a {
+		return r, fmt.Errorf("%w: invalid term range [%d, %d)", ErrInvalidSplit, r.a, r.b)
+	}
+
+	values := [3]*big.Int{}
+	for i := range values {
+		size, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < size {
+			return r, fmt.Errorf("%w: truncated value", ErrInvalidSplit)
+		}
+		data = data[n:]
+
+		values[i] = new(big.Int)
+		if err := values[i].GobDecode(data[:size]); err != nil {
+			return r, fmt.Errorf("%w: %v", ErrInvalidSplit, err)
+		}
+		data = data[size:]
+	}
+	if len(data) != 0 {
+		return r, fmt.Errorf("%w: %d trailing bytes", ErrInvalidSplit, len(data))
+	}
+
+	r.P, r.Q, r.R = values[0], values[1], values[2]
+	return r, nil
+}
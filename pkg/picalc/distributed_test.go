@@ -0,0 +1,91 @@
+package picalc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCombineSerialized(t *testing.T) {
+	A, B, C3_24 := chudnovskyConstants()
+	_, expectedQ, expectedR := binarySplitSerial(0, 100, A, B, C3_24, nil)
+
+	left, err := BinarySplitRange(0, 50)
+	if err != nil {
+		t.Fatalf("Failed to split [0, 50): %v", err)
+	}
+	right, err := BinarySplitRange(50, 100)
+	if err != nil {
+		t.Fatalf("Failed to split [50, 100): %v", err)
+	}
+
+	// Order of the parts does not matter
+	for _, parts := range [][][]byte{{left, right}, {right, left}} {
+		Q, R, err := CombineSerialized(parts)
+		if err != nil {
+			t.Fatalf("Failed to combine: %v", err)
+		}
+		if Q.Cmp(expectedQ) != 0 || R.Cmp(expectedR) != 0 {
+			t.Error("Combined Q and R differ from the direct result")
+		}
+	}
+
+	gap, _ := BinarySplitRange(60, 100)
+	for name, parts := range map[string][][]byte{
+		"Empty":     nil,
+		"Gap":       {left, gap},
+		"NoStart":   {right},
+		"Truncated": {left, right[:len(right)-1]},
+		"Version":   {append([]byte{99}, left[1:]...)},
+	} {
+		if _, _, err := CombineSerialized(parts); !errors.Is(err, ErrInvalidSplit) {
+			t.Errorf("%s: expected ErrInvalidSplit, got %v", name, err)
+		}
+	}
+
+	if _, err := BinarySplitRange(10, 5); err == nil {
+		t.Error("Expected an error for a reversed range")
+	}
+}
+
+func TestPartitionTerms(t *testing.T) {
+	for _, parts := range []int{-1, 1, 3, 7, 1000} {
+		ranges := PartitionTerms(1000, parts)
+		terms := chudnovskyTerms(1000 + initialGuardDigits)
+		if want := min(int64(max(parts, 1)), terms); int64(len(ranges)) != want {
+			t.Fatalf("%d parts: expected %d ranges, got %d", parts, want, len(ranges))
+		}
+
+		// No gaps or overlaps, and balanced
+		var next int64
+		for _, r := range ranges {
+			if r[0] != next || r[1] <= r[0] || r[1]-r[0] > terms/int64(len(ranges))+1 {
+				t.Fatalf("%d parts: unexpected range %v in %v", parts, r, ranges)
+			}
+			next = r[1]
+		}
+		if next != terms {
+			t.Fatalf("%d parts: ranges end at %d, expected %d", parts, next, terms)
+		}
+	}
+
+	// Combining every partition gives the whole series
+	A, B, C3_24 := chudnovskyConstants()
+	ranges := PartitionTerms(1000, 4)
+	_, expectedQ, expectedR := binarySplitSerial(0, ranges[len(ranges)-1][1], A, B, C3_24, nil)
+
+	var parts [][]byte
+	for _, r := range ranges {
+		data, err := BinarySplitRange(r[0], r[1])
+		if err != nil {
+			t.Fatalf("Failed to split %v: %v", r, err)
+		}
+		parts = append(parts, data)
+	}
+	Q, R, err := CombineSerialized(parts)
+	if err != nil {
+		t.Fatalf("Failed to combine: %v", err)
+	}
+	if Q.Cmp(expectedQ) != 0 || R.Cmp(expectedR) != 0 {
+		t.Error("Combined partitions differ from the whole series")
+	}
+}
@@ -0,0 +1,95 @@
+package picalc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// jsonFileChunkSize is the length of each digit string WriteDigits writes
+// in the json encoding
+const jsonFileChunkSize = 1 << 16
+
+// WriteDigits writes the first n digits of pi to path in the given encoding:
+//
+//   - "ascii": text as written by WriteDigitsToFile
+//   - "binary" or "packed": two digits per byte as written by WriteDigitsBinary
+//   - "json": an array of digit strings as written by EncodeJSONStream
+//
+// Every encoding is written atomically. Other encodings are rejected.
+func WriteDigits(pi *Pi, n int, path, encoding string) error {
+	switch encoding {
+	case "ascii":
+		return WriteDigitsToFile(pi.GetDigits(n), path)
+	case "binary", "packed":
+		return WriteDigitsBinary(pi.GetDigits(n), path)
+	case "json":
+		return writeFileAtomic(path, func(w io.Writer) error {
+			return pi.EncodeJSONStream(w, n, jsonFileChunkSize)
+		})
+	default:
+		return fmt.Errorf("unknown encoding %q: must be ascii, binary, packed or json", encoding)
+	}
+}
+
+// EncodeJSONStream writes the first n digits of Pi to w as a JSON array of
+// digit strings, each at most chunkSize digits long, e.g. ["31415","92653"].
+// Chunks are flushed as they are produced so clients can render progressively.
+func (p *Pi) EncodeJSONStream(w io.Writer, n int, chunkSize int) error {
+	if chunkSize <= 0 {
+		return fmt.Errorf("chunk size must be positive, got %d", chunkSize)
+	}
+
+	bw := bufio.NewWriter(w)
+	chunk := make([]byte, 0, chunkSize+2)
+	first := true
+
+	writeChunk := func() error {
+		if !first {
+			if err := bw.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		chunk = append(chunk, '"')
+		if _, err := bw.Write(chunk); err != nil {
+			return err
+		}
+		chunk = chunk[:0]
+		return bw.Flush()
+	}
+
+	if err := bw.WriteByte('['); err != nil {
+		return fmt.Errorf("error writing JSON stream: %w", err)
+	}
+
+	for d := range p.Digits(n) {
+		if len(chunk) == 0 {
+			chunk = append(chunk, '"')
+		}
+		chunk = append(chunk, '0'+byte(d))
+
+		if len(chunk) == chunkSize+1 {
+			if err := writeChunk(); err != nil {
+				return fmt.Errorf("error writing JSON stream: %w", err)
+			}
+		}
+	}
+
+	if len(chunk) > 0 {
+		if err := writeChunk(); err != nil {
+			return fmt.Errorf("error writing JSON stream: %w", err)
+		}
+	}
+
+	if err := bw.WriteByte(']'); err != nil {
+		return fmt.Errorf("error writing JSON stream: %w", err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("error writing JSON stream: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package picalc
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeJSONStream(t *testing.T) {
+	pi := NewPi(100)
+	CalculatePi(100, pi)
+
+	var buf bytes.Buffer
+	if err := pi.EncodeJSONStream(&buf, 100, 7); err != nil {
+		t.Fatalf("Failed to encode JSON stream: %v", err)
+	}
+
+	var chunks []string
+	if err := json.Unmarshal(buf.Bytes(), &chunks); err != nil {
+		t.Fatalf("Stream is not valid JSON: %v\n%s", err, buf.String())
+	}
+
+	var expected strings.Builder
+	for _, d := range pi.GetDigits(100) {
+		expected.WriteByte('0' + byte(d))
+	}
+
+	if got := strings.Join(chunks, ""); got != expected.String() {
+		t.Errorf("Concatenated chunks mismatch.\nExpected: %s\nGot: %s", expected.String(), got)
+	}
+
+	for i, c := range chunks {
+		if len(c) > 7 {
+			t.Errorf("Chunk %d exceeds chunk size: %q", i, c)
+		}
+	}
+
+	// Empty output is still a valid array
+	buf.Reset()
+	if err := pi.EncodeJSONStream(&buf, 0, 7); err != nil {
+		t.Fatalf("Failed to encode empty stream: %v", err)
+	}
+	if buf.String() != "[]" {
+		t.Errorf("Expected empty array, got %s", buf.String())
+	}
+
+	if err := pi.EncodeJSONStream(&buf, 10, 0); err == nil {
+		t.Error("Expected an error for a zero chunk size")
+	}
+}
+
+func TestWriteDigits(t *testing.T) {
+	pi := NewPi(100)
+	CalculatePi(100, pi)
+	expected := pi.GetDigits(51)
+	dir := t.TempDir()
+
+	for _, encoding := range []string{"ascii", "binary", "packed", "json"} {
+		path := filepath.Join(dir, "pi."+encoding)
+		if err := WriteDigits(pi, 51, path, encoding); err != nil {
+			t.Fatalf("%s: failed to write: %v", encoding, err)
+		}
+
+		var got []int
+		switch encoding {
+		case "ascii", "binary", "packed":
+			// Both text and binary files verify against the digits
+			if err := VerifyDigitsFile(expected, path); err != nil {
+				t.Errorf("%s: %v", encoding, err)
+			}
+			if encoding == "ascii" {
+				continue
+			}
+			var err error
+			if got, err = ReadDigitsBinary(path); err != nil {
+				t.Fatalf("%s: failed to read: %v", encoding, err)
+			}
+		case "json":
+			data, _ := os.ReadFile(path)
+			var chunks []string
+			if err := json.Unmarshal(data, &chunks); err != nil {
+				t.Fatalf("json: file is not valid JSON: %v", err)
+			}
+			for _, c := range strings.Join(chunks, "") {
+				got = append(got, int(c-'0'))
+			}
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: read back %v, expected %v", encoding, got, expected)
+		}
+	}
+
+	path := filepath.Join(dir, "pi.xml")
+	if err := WriteDigits(pi, 51, path, "xml"); err == nil || !strings.Contains(err.Error(), "unknown encoding") {
+		t.Errorf("Expected an unknown encoding error, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("An unknown encoding should not create a file")
+	}
+}
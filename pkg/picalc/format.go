@@ -0,0 +1,113 @@
+package picalc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatGrouped formats digits as "3." followed by the fractional digits split
+// into groups of groupSize joined by sep, e.g. "3.14159 26535 89793".
+// A groupSize of 0 or less disables grouping. The leading "3." is never grouped.
+func FormatGrouped(digits []int, groupSize int, sep string) string {
+	if len(digits) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fractional := len(digits) - 1
+	size := 2 + fractional
+	if groupSize > 0 && fractional > 0 {
+		size += (fractional - 1) / groupSize * len(sep)
+	}
+	sb.Grow(size)
+
+	sb.WriteByte('0' + byte(digits[0]))
+	sb.WriteByte('.')
+	for i := 1; i < len(digits); i++ {
+		if groupSize > 0 && i > 1 && (i-1)%groupSize == 0 {
+			sb.WriteString(sep)
+		}
+		sb.WriteByte('0' + byte(digits[i]))
+	}
+
+	return sb.String()
+}
+
+// ParseDecimalSep checks that s is usable as a decimal separator, such as
+// "," for "3,14159", and returns it. It must be a single printable ASCII
+// character other than a digit or a space.
+func ParseDecimalSep(s string) (byte, error) {
+	if len(s) != 1 || !isDecimalSep(s[0]) {
+		return 0, fmt.Errorf("decimal separator must be a single non-digit character, got %q", s)
+	}
+	return s[0], nil
+}
+
+// isDecimalSep reports whether c is a separator ParseDecimalSep accepts
+func isDecimalSep(c byte) bool {
+	return c > ' ' && c < 0x7f && (c < '0' || c > '9')
+}
+
+// WithDecimalSep replaces the "." after the leading 3 of text formatted by
+// FormatGrouped or GetString with sep, e.g. "3,14159" for ','. Text without
+// that "." and a sep of 0 are returned unchanged.
+func WithDecimalSep(text string, sep byte) string {
+	if sep == 0 || len(text) < 2 || text[1] != '.' {
+		return text
+	}
+	return text[:1] + string(sep) + text[2:]
+}
+
+// normalizeDecimalSep returns text with a decimal separator other than "."
+// after its leading digit replaced by ".", so files written with
+// TextOptions.DecimalSep checksum and verify like any other
+func normalizeDecimalSep(text []byte) []byte {
+	if len(text) < 2 || text[1] == '.' || text[0] < '0' || text[0] > '9' || !isDecimalSep(text[1]) {
+		return text
+	}
+	out := append([]byte(nil), text...)
+	out[1] = '.'
+	return out
+}
+
+// FormatFractional formats only the fractional digits, omitting the leading "3."
+func FormatFractional(digits []int) string {
+	if len(digits) <= 1 {
+		return ""
+	}
+	return FormatPlain(digits[1:])
+}
+
+// FormatPlain formats digits as they are, with no decimal point
+func FormatPlain(digits []int) string {
+	buf := make([]byte, len(digits))
+	for i, d := range digits {
+		buf[i] = '0' + byte(d)
+	}
+	return string(buf)
+}
+
+// GetString returns the first n digits of Pi formatted as "3.1415...",
+// where n counts the leading 3 as GetDigits does
+func (p *Pi) GetString(n int) string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	n = min(n, len(p.digits))
+	if n <= 0 {
+		return ""
+	}
+
+	buf := make([]byte, 0, n+1)
+	buf = append(buf, '0'+byte(p.digits[0]), '.')
+	for _, d := range p.digits[1:n] {
+		buf = append(buf, '0'+byte(d))
+	}
+	return string(buf)
+}
+
+// String returns all computed digits formatted as "3.1415...".
+// It implements fmt.Stringer.
+func (p *Pi) String() string {
+	return p.GetString(p.numDigits())
+}
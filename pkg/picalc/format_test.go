@@ -0,0 +1,95 @@
+package picalc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFormatGrouped(t *testing.T) {
+	digits := []int{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5, 8, 9, 7, 9, 3}
+
+	tests := []struct {
+		name      string
+		groupSize int
+		sep       string
+		expected  string
+	}{
+		{"NoGrouping", 0, " ", "3.141592653589793"},
+		{"GroupsOfFive", 5, " ", "3.14159 26535 89793"},
+		{"GroupsOfThree", 3, ",", "3.141,592,653,589,793"},
+		{"PartialLastGroup", 4, " ", "3.1415 9265 3589 793"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatGrouped(digits, tt.groupSize, tt.sep); got != tt.expected {
+				t.Errorf("Expected: %s\nGot: %s", tt.expected, got)
+			}
+		})
+	}
+
+	if got := FormatGrouped([]int{3}, 5, " "); got != "3." {
+		t.Errorf("Expected \"3.\" for no fractional digits, got %q", got)
+	}
+}
+
+func TestDecimalSep(t *testing.T) {
+	for _, bad := range []string{"", ",,", "5", " ", "\n", "·"} {
+		if _, err := ParseDecimalSep(bad); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+	sep, err := ParseDecimalSep(",")
+	if err != nil || sep != ',' {
+		t.Fatalf("Expected ',' to be accepted, got %q, %v", sep, err)
+	}
+
+	digits := []int{3, 1, 4, 1, 5, 9}
+	if got := WithDecimalSep(FormatGrouped(digits, 0, ""), sep); got != "3,14159" {
+		t.Errorf("Expected 3,14159, got %q", got)
+	}
+	if got := WithDecimalSep("14159", sep); got != "14159" {
+		t.Errorf("Expected fractional-only text unchanged, got %q", got)
+	}
+	if got := WithDecimalSep("3.14159", 0); got != "3.14159" {
+		t.Errorf("Expected no separator to leave the text unchanged, got %q", got)
+	}
+}
+
+func TestFormatFractional(t *testing.T) {
+	pi := NewPi(10)
+	CalculatePi(10, pi)
+
+	if got := FormatFractional(pi.GetDigits(11)); got != "1415926535" {
+		t.Errorf("Expected 1415926535, got %s", got)
+	}
+	if got := FormatFractional([]int{3}); got != "" {
+		t.Errorf("Expected no fractional digits, got %q", got)
+	}
+	if got := FormatPlain([]int{9, 2, 6}); got != "926" {
+		t.Errorf("Expected 926, got %s", got)
+	}
+}
+
+func TestGetString(t *testing.T) {
+	pi := NewPi(20)
+	CalculatePi(20, pi)
+
+	if got := pi.GetString(10); got != "3.141592653" {
+		t.Errorf("Expected 3.141592653, got %s", got)
+	}
+	if got := pi.GetString(1); got != "3." {
+		t.Errorf("Expected \"3.\", got %q", got)
+	}
+	if got := pi.GetString(0); got != "" {
+		t.Errorf("Expected an empty string, got %q", got)
+	}
+
+	// The Stringer form has every digit and matches FormatGrouped
+	if got := fmt.Sprint(pi); got != "3.14159265358979323846" {
+		t.Errorf("Expected 3.14159265358979323846, got %s", got)
+	}
+	if pi.String() != FormatGrouped(pi.GetDigits(21), 0, "") {
+		t.Error("String and FormatGrouped disagree")
+	}
+}
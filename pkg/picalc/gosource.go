@@ -0,0 +1,34 @@
+package picalc
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+)
+
+// GenerateGoSource writes a Go source file in package pkg that declares the
+// string constant varName holding digits formatted as "3.1415...", for
+// projects that embed Pi at a fixed precision
+func GenerateGoSource(digits []int, pkg, varName string, w io.Writer) error {
+	if !token.IsIdentifier(pkg) {
+		return fmt.Errorf("invalid package name %q", pkg)
+	}
+	if !token.IsIdentifier(varName) {
+		return fmt.Errorf("invalid constant name %q", varName)
+	}
+	if len(digits) == 0 {
+		return fmt.Errorf("no digits to generate")
+	}
+
+	_, err := fmt.Fprintf(w, `// Code generated by picalc gen-go; DO NOT EDIT.
+
+package %s
+
+// %s holds π to %d decimal places
+const %s = "%s"
+`, pkg, varName, len(digits)-1, varName, FormatGrouped(digits, 0, ""))
+	if err != nil {
+		return fmt.Errorf("error writing Go source: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package picalc
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGoSource(t *testing.T) {
+	pi := NewPi(50)
+	CalculatePi(50, pi)
+
+	var buf bytes.Buffer
+	if err := GenerateGoSource(pi.GetDigits(51), "foo", "PiDigits", &buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), "pi_gen.go", buf.Bytes(), parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Generated source does not parse: %v\n%s", err, buf.String())
+	}
+	if f.Name.Name != "foo" {
+		t.Errorf("Expected package foo, got %s", f.Name.Name)
+	}
+	if !ast.IsGenerated(f) {
+		t.Error("Expected the file to be marked as generated")
+	}
+
+	decl := f.Decls[0].(*ast.GenDecl).Specs[0].(*ast.ValueSpec)
+	value := decl.Values[0].(*ast.BasicLit).Value
+	if decl.Names[0].Name != "PiDigits" || !strings.HasPrefix(value, `"3.14159265358979`) || len(value) != 54 {
+		t.Errorf("Unexpected constant %s = %s", decl.Names[0].Name, value)
+	}
+
+	for _, tt := range []struct{ pkg, name string }{{"my-pkg", "Pi"}, {"foo", "1Pi"}, {"foo", ""}} {
+		if err := GenerateGoSource(pi.GetDigits(51), tt.pkg, tt.name, &buf); err == nil {
+			t.Errorf("Expected an error for package %q, name %q", tt.pkg, tt.name)
+		}
+	}
+}
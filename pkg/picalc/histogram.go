@@ -0,0 +1,92 @@
+package picalc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// parallelFrequencyMin is the number of digits above which DigitFrequency
+// counts in parallel; below it starting goroutines costs more than it saves
+const parallelFrequencyMin = 1 << 20
+
+// DigitFrequency counts how often each decimal digit 0-9 occurs in digits,
+// using DigitFrequencyParallel for large inputs
+func DigitFrequency(digits []int) [10]int {
+	var counts [10]int
+	if len(digits) >= parallelFrequencyMin {
+		for d, count := range DigitFrequencyParallel(digits, 0) {
+			counts[d] = int(count)
+		}
+		return counts
+	}
+
+	for _, d := range digits {
+		if d >= 0 && d <= 9 {
+			counts[d]++
+		}
+	}
+	return counts
+}
+
+// DigitFrequencyParallel counts digits like DigitFrequency with workers
+// goroutines, each counting a contiguous chunk before the counts are summed.
+// A workers value of 0 or less uses GOMAXPROCS.
+func DigitFrequencyParallel(digits []int, workers int) [10]int64 {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	chunk := (len(digits) + workers - 1) / workers
+	partial := make([][10]int64, workers)
+	var wg sync.WaitGroup
+
+	for worker := 0; worker < workers && worker*chunk < len(digits); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var local [10]int64
+			for _, d := range digits[worker*chunk : min((worker+1)*chunk, len(digits))] {
+				if d >= 0 && d <= 9 {
+					local[d]++
+				}
+			}
+			partial[worker] = local
+		}()
+	}
+	wg.Wait()
+
+	var counts [10]int64
+	for _, local := range partial {
+		for d, count := range local {
+			counts[d] += count
+		}
+	}
+	return counts
+}
+
+// WriteDigitHistogramCSV writes the digit frequencies of digits to w as CSV
+// with a "digit,count,percentage" header and a row per digit 0-9. With no
+// digits every count and percentage is 0.
+func WriteDigitHistogramCSV(digits []int, w io.Writer) error {
+	counts := DigitFrequency(digits)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"digit", "count", "percentage"})
+	for d, count := range counts {
+		percentage := 0.0
+		if len(digits) > 0 {
+			percentage = float64(count) / float64(len(digits)) * 100
+		}
+		cw.Write([]string{strconv.Itoa(d), strconv.Itoa(count), strconv.FormatFloat(percentage, 'f', 4, 64)})
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("error writing histogram: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package picalc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDigitFrequency(t *testing.T) {
+	counts := DigitFrequency([]int{3, 1, 4, 1, 5, 9})
+	expected := [10]int{0, 2, 0, 1, 1, 1, 0, 0, 0, 1}
+	if counts != expected {
+		t.Errorf("Expected %v, got %v", expected, counts)
+	}
+}
+
+// pseudoDigits returns n digits from a fixed linear congruential sequence
+func pseudoDigits(n int) []int {
+	digits := make([]int, n)
+	x := uint32(1)
+	for i := range digits {
+		x = x*1664525 + 1013904223
+		digits[i] = int(x>>16) % 10
+	}
+	return digits
+}
+
+func TestDigitFrequencyParallel(t *testing.T) {
+	for _, n := range []int{0, 1, 999, parallelFrequencyMin + 3} {
+		digits := pseudoDigits(n)
+
+		var expected [10]int64
+		for _, d := range digits {
+			expected[d]++
+		}
+
+		for _, workers := range []int{0, 1, 3, 7, 2000} {
+			if got := DigitFrequencyParallel(digits, workers); got != expected {
+				t.Errorf("%d digits, %d workers: expected %v, got %v", n, workers, expected, got)
+			}
+		}
+
+		counts := DigitFrequency(digits)
+		for d := range counts {
+			if int64(counts[d]) != expected[d] {
+				t.Errorf("%d digits: DigitFrequency counted %d %ds, expected %d", n, counts[d], d, expected[d])
+			}
+		}
+	}
+}
+
+func BenchmarkDigitFrequency(b *testing.B) {
+	digits := pseudoDigits(10_000_000)
+
+	b.Run("Serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			DigitFrequencyParallel(digits, 1)
+		}
+	})
+	b.Run("Parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			DigitFrequencyParallel(digits, 0)
+		}
+	})
+}
+
+func TestWriteDigitHistogramCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDigitHistogramCSV([]int{3, 1, 4, 1}, &buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "digit,count,percentage\n" +
+		"0,0,0.0000\n" +
+		"1,2,50.0000\n" +
+		"2,0,0.0000\n" +
+		"3,1,25.0000\n" +
+		"4,1,25.0000\n" +
+		"5,0,0.0000\n" +
+		"6,0,0.0000\n" +
+		"7,0,0.0000\n" +
+		"8,0,0.0000\n" +
+		"9,0,0.0000\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected CSV:\n%s", buf.String())
+	}
+
+	// No digits still gives a row per digit, without dividing by zero
+	buf.Reset()
+	if err := WriteDigitHistogramCSV(nil, &buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("9,0,0.0000\n")) {
+		t.Errorf("Unexpected CSV for no digits:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,75 @@
+package picalc
+
+// feynmanRun is the run of 9s known as the Feynman point
+const feynmanRun = 6
+
+// Landmark is a notable pattern in the digits of Pi. Position counts the
+// leading 3 as position 0, so it is also the decimal place of the first digit.
+type Landmark struct {
+	Name     string
+	Position int
+	Length   int
+	Digits   string
+}
+
+// FindLandmarks scans digits, the leading 3 followed by fractional digits as
+// returned by GetDigits, for the Feynman point (the first six consecutive
+// 9s), the longest run of a repeated digit and the longest palindrome among
+// the fractional digits. Landmarks that don't occur are omitted.
+func FindLandmarks(digits []int) []Landmark {
+	var landmarks []Landmark
+	if len(digits) < 2 {
+		return landmarks
+	}
+	frac := digits[1:]
+
+	// Runs of a repeated digit, noting the first run of six 9s on the way
+	bestStart, bestLen := 0, 1
+	feynman := -1
+	for start := 0; start < len(frac); {
+		end := start + 1
+		for end < len(frac) && frac[end] == frac[start] {
+			end++
+		}
+		if end-start > bestLen {
+			bestStart, bestLen = start, end-start
+		}
+		if feynman < 0 && frac[start] == 9 && end-start >= feynmanRun {
+			feynman = start
+		}
+		start = end
+	}
+
+	if feynman >= 0 {
+		landmarks = append(landmarks, landmark("Feynman point", digits, feynman+1, feynmanRun))
+	}
+	landmarks = append(landmarks, landmark("Longest repeated digit run", digits, bestStart+1, bestLen))
+
+	// Palindromes, expanding around each centre
+	palStart, palLen := 0, 1
+	for centre := 0; centre < len(frac); centre++ {
+		for _, right := range []int{centre, centre + 1} {
+			left := centre
+			for left >= 0 && right < len(frac) && frac[left] == frac[right] {
+				left--
+				right++
+			}
+			if n := right - left - 1; n > palLen {
+				palStart, palLen = left+1, n
+			}
+		}
+	}
+	landmarks = append(landmarks, landmark("Longest palindrome", digits, palStart+1, palLen))
+
+	return landmarks
+}
+
+// landmark describes the length digits starting at position
+func landmark(name string, digits []int, position, length int) Landmark {
+	return Landmark{
+		Name:     name,
+		Position: position,
+		Length:   length,
+		Digits:   FormatPlain(digits[position : position+length]),
+	}
+}
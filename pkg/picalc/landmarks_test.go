@@ -0,0 +1,49 @@
+package picalc
+
+import "testing"
+
+func TestFindLandmarks(t *testing.T) {
+	pi := NewPi(770)
+	CalculatePi(770, pi)
+
+	found := make(map[string]Landmark)
+	for _, l := range FindLandmarks(pi.GetDigits(771)) {
+		found[l.Name] = l
+	}
+
+	feynman, ok := found["Feynman point"]
+	if !ok {
+		t.Fatal("Expected to find the Feynman point")
+	}
+	if feynman.Position != 762 || feynman.Digits != "999999" {
+		t.Errorf("Expected 999999 at position 762, got %+v", feynman)
+	}
+
+	if run := found["Longest repeated digit run"]; run.Position != 762 || run.Length != 6 {
+		t.Errorf("Expected the longest run to be the Feynman point, got %+v", run)
+	}
+}
+
+func TestFindLandmarksSmall(t *testing.T) {
+	// 3.1 2 3 4 5 4 3 7 7
+	landmarks := FindLandmarks([]int{3, 1, 2, 3, 4, 5, 4, 3, 7, 7})
+
+	found := make(map[string]Landmark)
+	for _, l := range landmarks {
+		found[l.Name] = l
+	}
+
+	if _, ok := found["Feynman point"]; ok {
+		t.Error("Expected no Feynman point")
+	}
+	if run := found["Longest repeated digit run"]; run.Position != 8 || run.Digits != "77" {
+		t.Errorf("Unexpected run: %+v", run)
+	}
+	if pal := found["Longest palindrome"]; pal.Position != 3 || pal.Digits != "34543" {
+		t.Errorf("Unexpected palindrome: %+v", pal)
+	}
+
+	if landmarks := FindLandmarks([]int{3}); len(landmarks) != 0 {
+		t.Errorf("Expected no landmarks without fractional digits, got %v", landmarks)
+	}
+}
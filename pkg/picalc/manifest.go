@@ -0,0 +1,184 @@
+package picalc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Manifest describes a digits file written by WriteDigitsWithManifest
+type Manifest struct {
+	Precision int64     `json:"precision"`
+	Digits    int       `json:"digits"`
+	Algorithm string    `json:"algorithm"`
+	Duration  string    `json:"duration"`
+	Checksum  string    `json:"checksum"`
+	Version   string    `json:"version"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ManifestPath returns the sidecar manifest path for a digits file
+func ManifestPath(path string) string {
+	return path + ".json"
+}
+
+// Checksum returns the hex encoded SHA-256 of digits as written by WriteDigitsToFile
+func Checksum(digits []int) string {
+	h := sha256.New()
+	if len(digits) > 0 {
+		h.Write([]byte{'0' + byte(digits[0]), '.'})
+	}
+
+	buf := make([]byte, 0, 1000)
+	for i := 1; i < len(digits); i++ {
+		buf = append(buf, '0'+byte(digits[i]))
+		if len(buf) == cap(buf) {
+			h.Write(buf)
+			buf = buf[:0]
+		}
+	}
+	h.Write(buf)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ChecksumText returns the checksum of digit text in either the full "3.1415..."
+// or the fractional-only "1415..." form, always hashing the full form so both
+// agree with Checksum. The full form may have any integer digit, such as the
+// 6 of τ; the fractional-only form is taken to be π's. Line breaks from
+// TextOptions.LineWidth are ignored, whether LF or CRLF, and a
+// TextOptions.DecimalSep is hashed as ".".
+func ChecksumText(text []byte) string {
+	return checksumText(normalizeDecimalSep(stripLineBreaks(text)), '3')
+}
+
+// checksumText hashes normalized digit text, prefixing fractional-only text
+// with the integer digit lead and "."
+func checksumText(text []byte, lead byte) string {
+	h := sha256.New()
+	if !hasIntegerPart(text) {
+		h.Write([]byte{lead, '.'})
+	}
+	h.Write(text)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hasIntegerPart reports whether normalized digit text starts with an
+// integer digit and ".", as in "3.1415...", rather than being fractional-only
+func hasIntegerPart(text []byte) bool {
+	return len(text) >= 2 && text[0] >= '0' && text[0] <= '9' && text[1] == '.'
+}
+
+// stripLineBreaks removes the LF and CRLF line breaks TextOptions can add
+func stripLineBreaks(text []byte) []byte {
+	text = bytes.ReplaceAll(text, []byte("\r"), nil)
+	return bytes.ReplaceAll(text, []byte("\n"), nil)
+}
+
+// ErrVerifyFailed is returned by VerifyDigitsFile when a file does not hold the expected digits
+var ErrVerifyFailed = errors.New("digits file verification failed")
+
+// VerifyDigitsFile reads back a file written by WriteDigitsToFile,
+// WriteDigitsToFileWithOptions or WriteDigitsBinary and checks it holds exactly
+// digits, catching truncated or corrupted writes. Both text forms, with any
+// integer digit such as τ's 6, LF or CRLF line breaks, any decimal separator
+// and gzip compression are accepted.
+func VerifyDigitsFile(digits []int, filename string) error {
+	text, err := readDigitFile(filename)
+	if err != nil {
+		return fmt.Errorf("error reading file: %v", err)
+	}
+
+	if isDigitsBinary(text) {
+		stored, err := decodeDigitsBinary(text)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrVerifyFailed, err)
+		}
+		text = []byte(FormatGrouped(stored, 0, ""))
+	}
+	text = normalizeDecimalSep(stripLineBreaks(text))
+
+	// The fractional-only form omits the integer part but still stands for it
+	count := len(text) + 1
+	if hasIntegerPart(text) {
+		count = len(text) - 1
+	}
+	if count != len(digits) {
+		return fmt.Errorf("%w: %s holds %d digits, expected %d", ErrVerifyFailed, filename, count, len(digits))
+	}
+
+	if sum, expected := checksumText(text, '0'+byte(digits[0])), Checksum(digits); sum != expected {
+		return fmt.Errorf("%w: %s has checksum %s, expected %s", ErrVerifyFailed, filename, sum, expected)
+	}
+
+	return nil
+}
+
+// Checksum returns the SHA-256 of all computed digits as written by
+// WriteDigitsToFile. With Options.StreamChecksum the digest recorded during
+// extraction is returned; otherwise it is computed from the digits.
+func (p *Pi) Checksum() string {
+	p.mutex.RLock()
+	sum := p.checksum
+	p.mutex.RUnlock()
+
+	if sum != "" {
+		return sum
+	}
+	return Checksum(p.GetDigits(p.numDigits()))
+}
+
+// WriteDigitsWithManifest writes the first n digits of pi to path along with
+// a JSON manifest next to it describing how they were computed
+func WriteDigitsWithManifest(pi *Pi, n int, path string) error {
+	digits := pi.GetDigits(n)
+	if err := WriteDigitsToFile(digits, path); err != nil {
+		return err
+	}
+
+	m := Manifest{
+		Precision: pi.currentPrecision(),
+		Digits:    len(digits),
+		Algorithm: pi.algorithm.String(),
+		Duration:  pi.Duration().String(),
+		Checksum:  Checksum(digits),
+		Version:   VERSION,
+		Timestamp: time.Now().UTC(),
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding manifest: %v", err)
+	}
+
+	err = writeFileAtomic(ManifestPath(path), func(w io.Writer) error {
+		_, err := w.Write(append(data, '\n'))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error writing manifest: %w", err)
+	}
+
+	return nil
+}
+
+// ReadManifest reads the manifest written for the digits file at path
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(ManifestPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest: %v", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("error decoding manifest: %v", err)
+	}
+
+	return &m, nil
+}
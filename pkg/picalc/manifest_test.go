@@ -0,0 +1,169 @@
+package picalc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	pi := NewPi(50)
+	CalculatePi(50, pi)
+
+	path := filepath.Join(t.TempDir(), "pi.txt")
+	if err := WriteDigitsWithManifest(pi, 50, path); err != nil {
+		t.Fatalf("Failed to write digits with manifest: %v", err)
+	}
+
+	m, err := ReadManifest(path)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+
+	if m.Precision != 50 || m.Digits != 50 {
+		t.Errorf("Unexpected precision/digits in manifest: %d/%d", m.Precision, m.Digits)
+	}
+	if m.Version != VERSION {
+		t.Errorf("Manifest version mismatch.\nExpected: %s\nGot: %s", VERSION, m.Version)
+	}
+	if m.Algorithm == "" || m.Duration == "" || m.Timestamp.IsZero() {
+		t.Errorf("Manifest is missing fields: %+v", m)
+	}
+
+	// The checksum must match a recomputation over the file contents
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read digits file: %v", err)
+	}
+	sum := sha256.Sum256(content)
+	if expected := hex.EncodeToString(sum[:]); m.Checksum != expected {
+		t.Errorf("Checksum mismatch.\nExpected: %s\nGot: %s", expected, m.Checksum)
+	}
+
+	// Both files are written atomically, leaving no temporary files behind
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 2 {
+		t.Errorf("Expected only the digits file and its manifest, got %d entries", len(entries))
+	}
+}
+
+func TestStreamedChecksum(t *testing.T) {
+	for _, precision := range []int64{10, 500} {
+		streamed := NewPiWithOptions(precision, Options{StreamChecksum: true})
+		CalculatePi(precision, streamed)
+
+		if streamed.checksum == "" {
+			t.Fatalf("Precision %d: expected a streamed checksum", precision)
+		}
+
+		expected := Checksum(streamed.GetDigits(int(precision) + 1))
+		if streamed.Checksum() != expected {
+			t.Errorf("Precision %d: streamed checksum mismatch.\nExpected: %s\nGot: %s", precision, expected, streamed.Checksum())
+		}
+
+		// Without streaming the same digest is computed on demand
+		plain := NewPi(precision)
+		CalculatePi(precision, plain)
+		if plain.checksum != "" || plain.Checksum() != expected {
+			t.Errorf("Precision %d: on demand checksum mismatch.\nExpected: %s\nGot: %s", precision, expected, plain.Checksum())
+		}
+	}
+}
+
+func TestChecksumTextForms(t *testing.T) {
+	pi := NewPi(100)
+	CalculatePi(100, pi)
+	digits := pi.GetDigits(101)
+
+	dir := t.TempDir()
+	full := filepath.Join(dir, "full.txt")
+	fractional := filepath.Join(dir, "fractional.txt")
+
+	if err := WriteDigitsToFile(digits, full); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := WriteDigitsToFileWithOptions(digits, fractional, TextOptions{FractionalOnly: true}); err != nil {
+		t.Fatalf("Failed to write fractional file: %v", err)
+	}
+
+	fullText, _ := os.ReadFile(full)
+	fractionalText, _ := os.ReadFile(fractional)
+	if string(fractionalText) != FormatFractional(digits) {
+		t.Errorf("Fractional file mismatch: %s", fractionalText)
+	}
+
+	expected := Checksum(digits)
+	if ChecksumText(fullText) != expected || ChecksumText(fractionalText) != expected {
+		t.Errorf("Checksums of both forms should equal %s, got %s and %s",
+			expected, ChecksumText(fullText), ChecksumText(fractionalText))
+	}
+}
+
+func TestVerifyDigitsFile(t *testing.T) {
+	pi := NewPi(100)
+	CalculatePi(100, pi)
+	digits := pi.GetDigits(101)
+	dir := t.TempDir()
+
+	for name, opts := range map[string]TextOptions{
+		"Full":       {},
+		"Fractional": {FractionalOnly: true},
+		"Wrapped":    {LineWidth: 10},
+	} {
+		path := filepath.Join(dir, name+".txt")
+		if err := WriteDigitsToFileWithOptions(digits, path, opts); err != nil {
+			t.Fatalf("%s: failed to write file: %v", name, err)
+		}
+		if err := VerifyDigitsFile(digits, path); err != nil {
+			t.Errorf("%s: verification should pass, got %v", name, err)
+		}
+	}
+
+	// τ files start with 6, in either form
+	tau := NewPi(50)
+	if err := CalculateTau(50, tau); err != nil {
+		t.Fatal(err)
+	}
+	tauDigits := tau.GetDigits(51)
+	for name, opts := range map[string]TextOptions{
+		"TauFull":       {},
+		"TauFractional": {FractionalOnly: true},
+	} {
+		path := filepath.Join(dir, name+".txt")
+		if err := WriteDigitsToFileWithOptions(tauDigits, path, opts); err != nil {
+			t.Fatalf("%s: failed to write file: %v", name, err)
+		}
+		if err := VerifyDigitsFile(tauDigits, path); err != nil {
+			t.Errorf("%s: verification should pass, got %v", name, err)
+		}
+	}
+	if err := VerifyDigitsFile(digits[:51], filepath.Join(dir, "TauFull.txt")); !errors.Is(err, ErrVerifyFailed) {
+		t.Errorf("Expected π digits not to verify against a τ file, got %v", err)
+	}
+
+	// A short write loses the tail of the file
+	path := filepath.Join(dir, "short.txt")
+	if err := WriteDigitsToFile(digits, path); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.Truncate(path, 50); err != nil {
+		t.Fatalf("Failed to truncate file: %v", err)
+	}
+	if err := VerifyDigitsFile(digits, path); !errors.Is(err, ErrVerifyFailed) {
+		t.Errorf("Expected ErrVerifyFailed for a truncated file, got %v", err)
+	}
+
+	// A corrupted digit keeps the count but changes the checksum
+	corrupted := append([]byte(pi.GetString(101)[:100]), '0')
+	if string(corrupted) == pi.GetString(101) {
+		corrupted[100] = '1'
+	}
+	if err := os.WriteFile(path, corrupted, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := VerifyDigitsFile(digits, path); !errors.Is(err, ErrVerifyFailed) {
+		t.Errorf("Expected ErrVerifyFailed for a corrupted file, got %v", err)
+	}
+}
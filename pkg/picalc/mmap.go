@@ -0,0 +1,44 @@
+package picalc
+
+import "fmt"
+
+// NewPiMmap creates a new Pi calculator whose digits are stored in a
+// memory-mapped file at path rather than on the heap, so the OS can page
+// them out once extracted. Each digit still takes an int in the file, and
+// the calculation itself builds its result on the heap, so peak memory is
+// not reduced. The file is created or truncated. Everything that reads or
+// writes digits works unchanged.
+// Call Close to unmap the file when done.
+func NewPiMmap(precision int64, path string) (*Pi, error) {
+	if precision < 0 {
+		return nil, fmt.Errorf("precision must be non-negative, got %d", precision)
+	}
+
+	digits, mapping, err := mmapDigits(path, int(precision)+1) // +1 for the '3' digit
+	if err != nil {
+		return nil, fmt.Errorf("error mapping %s: %w", path, err)
+	}
+
+	return &Pi{
+		digits:    digits,
+		mapping:   mapping,
+		precision: precision,
+	}, nil
+}
+
+// Close releases the memory-mapped digit storage of a Pi created with
+// NewPiMmap. The digits remain in the file. Closing a heap-backed Pi does
+// nothing. The Pi must not be used after Close.
+func (p *Pi) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.mapping == nil {
+		return nil
+	}
+
+	err := munmap(p.mapping)
+	p.mapping = nil
+	p.digits = nil
+	return err
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package picalc
+
+import "errors"
+
+var errMmapUnsupported = errors.New("memory-mapped digits are not supported on this platform")
+
+func mmapDigits(path string, n int) ([]int, []byte, error) {
+	return nil, nil, errMmapUnsupported
+}
+
+func munmap(mapping []byte) error {
+	return errMmapUnsupported
+}
@@ -0,0 +1,58 @@
+//go:build unix
+
+package picalc
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+func TestNewPiMmap(t *testing.T) {
+	const precision = 500
+	path := filepath.Join(t.TempDir(), "digits.mmap")
+
+	pi, err := NewPiMmap(precision, path)
+	if err != nil {
+		t.Fatalf("NewPiMmap failed: %v", err)
+	}
+	CalculatePi(precision, pi)
+
+	reference := NewPi(precision)
+	CalculatePi(precision, reference)
+
+	if !reflect.DeepEqual(pi.GetDigits(precision+1), reference.GetDigits(precision+1)) {
+		t.Error("Memory-mapped digits differ from heap digits")
+	}
+
+	// Writing goes through the mapping too
+	out := filepath.Join(t.TempDir(), "pi.txt")
+	if err := WriteDigitsToFile(pi.GetDigits(precision+1), out); err != nil {
+		t.Fatalf("Failed to write digits: %v", err)
+	}
+	if err := VerifyDigitsFile(reference.GetDigits(precision+1), out); err != nil {
+		t.Errorf("Written digits differ: %v", err)
+	}
+
+	if err := pi.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Backing file missing: %v", err)
+	}
+	if expected := int64((precision + 1) * unsafe.Sizeof(int(0))); info.Size() != expected {
+		t.Errorf("Expected backing file of %d bytes, got %d", expected, info.Size())
+	}
+
+	// Closing twice, or closing a heap Pi, is harmless
+	if err := pi.Close(); err != nil {
+		t.Errorf("Second Close failed: %v", err)
+	}
+	if err := reference.Close(); err != nil {
+		t.Errorf("Closing a heap Pi failed: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+package picalc
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// mmapDigits maps a file at path holding n digits and returns the digits
+// backed by the mapping along with the mapping itself
+func mmapDigits(path string, n int) ([]int, []byte, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	size := n * int(unsafe.Sizeof(int(0)))
+	if err := f.Truncate(int64(size)); err != nil {
+		return nil, nil, err
+	}
+
+	mapping, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return unsafe.Slice((*int)(unsafe.Pointer(&mapping[0])), n), mapping, nil
+}
+
+// munmap releases a mapping returned by mmapDigits
+func munmap(mapping []byte) error {
+	return syscall.Munmap(mapping)
+}
@@ -0,0 +1,297 @@
+package picalc
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRoundDecimal(t *testing.T) {
+	tests := []struct {
+		in       string
+		places   int
+		mode     RoundingMode
+		expected string
+	}{
+		// Exactly half in the last place
+		{"3.1415", 3, RoundDown, "3.141"},
+		{"3.1415", 3, RoundToNearest, "3.142"},
+		{"3.1415", 3, RoundUp, "3.142"},
+
+		// Below half
+		{"3.1414", 3, RoundDown, "3.141"},
+		{"3.1414", 3, RoundToNearest, "3.141"},
+		{"3.1414", 3, RoundUp, "3.142"},
+
+		// Nothing after the last place
+		{"3.1410", 3, RoundUp, "3.141"},
+
+		// Carry through nines and into the integer part
+		{"3.1999", 2, RoundUp, "3.20"},
+		{"9.999", 2, RoundToNearest, "10.00"},
+
+		// Not enough digits to round
+		{"3.14", 5, RoundUp, "3.14"},
+	}
+
+	for _, tt := range tests {
+		if got := roundDecimal(tt.in, tt.places, tt.mode); got != tt.expected {
+			t.Errorf("roundDecimal(%q, %d, %s) = %q, expected %q", tt.in, tt.places, tt.mode, got, tt.expected)
+		}
+	}
+}
+
+func TestLastDigitRounding(t *testing.T) {
+	// Pi = 3.141592653589|793..., so the 12th place rounds up with a carry
+	tests := []struct {
+		mode     RoundingMode
+		expected []int
+	}{
+		{RoundDown, []int{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5, 8, 9}},
+		{RoundToNearest, []int{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5, 9, 0}},
+		{RoundUp, []int{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5, 9, 0}},
+	}
+
+	for _, tt := range tests {
+		pi := NewPiWithOptions(12, Options{Rounding: tt.mode})
+		CalculatePi(12, pi)
+
+		if pi.LastDigitRounding() != tt.mode {
+			t.Errorf("Expected rounding mode %s, got %s", tt.mode, pi.LastDigitRounding())
+		}
+		if digits := pi.GetDigits(13); !reflect.DeepEqual(digits, tt.expected) {
+			t.Errorf("Rounding %s mismatch.\nExpected: %v\nGot: %v", tt.mode, tt.expected, digits)
+		}
+	}
+
+	// The hardcoded path honors rounding too: 3.14159|26...
+	pi := NewPiWithOptions(5, Options{Rounding: RoundUp})
+	CalculatePi(5, pi)
+	if digits := pi.GetDigits(6); !reflect.DeepEqual(digits, []int{3, 1, 4, 1, 6, 0}) {
+		t.Errorf("Hardcoded rounding mismatch, got %v", digits)
+	}
+}
+
+func TestReliableDigits(t *testing.T) {
+	if got := NewPi(100).ReliableDigits(); got != 0 {
+		t.Errorf("Expected no reliable digits before calculating, got %d", got)
+	}
+
+	// Without verification the tail is excluded
+	fast := NewPiWithOptions(100, Options{Fast: true})
+	CalculatePi(100, fast)
+	reliable := fast.ReliableDigits()
+	if reliable >= 101 || reliable < 95 {
+		t.Errorf("Expected slightly fewer than 101 reliable digits, got %d", reliable)
+	}
+	if _, err := fast.ValidateAgainstReference(reliable); err != nil {
+		t.Errorf("Reliable digits do not match the reference: %v", err)
+	}
+
+	// Verified digits are all reliable
+	pi := NewPi(100)
+	CalculatePi(100, pi)
+	if got := pi.ReliableDigits(); got != 101 {
+		t.Errorf("Expected all 101 verified digits to be reliable, got %d", got)
+	}
+
+	// Verification holds up across the six 9s at digit 762, where an
+	// unverified carry would change the digits before them
+	for precision := int64(758); precision <= 768; precision++ {
+		pi := NewPi(precision)
+		CalculatePi(precision, pi)
+		if got := pi.ReliableDigits(); got != int(precision)+1 {
+			t.Errorf("Precision %d: expected all %d verified digits to be reliable, got %d", precision, precision+1, got)
+		}
+		if _, err := pi.ValidateAgainstReference(pi.ReliableDigits()); err != nil {
+			t.Errorf("Precision %d: verified digits do not match the reference: %v", precision, err)
+		}
+	}
+
+	// 3.141592653589|79 rounds up to ...590, changing the last two digits
+	rounded := NewPiWithOptions(12, Options{Rounding: RoundUp})
+	CalculatePi(12, rounded)
+	if got := rounded.ReliableDigits(); got != 11 {
+		t.Errorf("Expected 11 reliable digits after a rounding carry, got %d", got)
+	}
+	if _, err := rounded.ValidateAgainstReference(rounded.ReliableDigits()); err != nil {
+		t.Errorf("Reliable rounded digits do not match the reference: %v", err)
+	}
+}
+
+func TestLastTimings(t *testing.T) {
+	pi := NewPiWithOptions(5000, Options{RecordTimings: true})
+	CalculatePi(5000, pi)
+
+	tm := pi.LastTimings()
+	phases := map[string]time.Duration{
+		"BinarySplit": tm.BinarySplit,
+		"Sqrt":        tm.Sqrt,
+		"Division":    tm.Division,
+		"Extraction":  tm.Extraction,
+	}
+
+	var sum time.Duration
+	for name, d := range phases {
+		if d < 0 {
+			t.Errorf("Phase %s has a negative duration: %v", name, d)
+		}
+		sum += d
+	}
+
+	if tm.Total <= 0 || sum > tm.Total {
+		t.Errorf("Phases (%v) should add up to no more than the total (%v)", sum, tm.Total)
+	}
+	if tm.Total-sum > tm.Total/2 {
+		t.Errorf("Phases (%v) should account for most of the total (%v)", sum, tm.Total)
+	}
+
+	// Disabled by default
+	plain := NewPi(100)
+	CalculatePi(100, plain)
+	if plain.LastTimings() != (Timings{}) {
+		t.Errorf("Expected no timings without RecordTimings, got %+v", plain.LastTimings())
+	}
+}
+
+func TestNoHardcode(t *testing.T) {
+	pi := NewPiWithOptions(5, Options{NoHardcode: true})
+	CalculatePi(5, pi)
+
+	if got := FormatPlain(pi.GetDigits(6)); got != "314159" {
+		t.Errorf("Expected 314159, got %s", got)
+	}
+	// The hardcoded path counts a single unit of work
+	if _, total := pi.GetTerms(); total <= 1 {
+		t.Errorf("Expected the series to run, got %d terms", total)
+	}
+
+	// The algorithm is correct at every small precision
+	for precision := int64(1); precision <= 10; precision++ {
+		pi := NewPiWithOptions(precision, Options{NoHardcode: true})
+		CalculatePi(precision, pi)
+		if _, err := pi.ValidateAgainstReference(int(precision) + 1); err != nil {
+			t.Errorf("Precision %d: %v", precision, err)
+		}
+	}
+}
+
+func TestLastTrace(t *testing.T) {
+	// Workers are only spawned with more than one P
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(2))
+
+	pi := NewPiWithOptions(5000, Options{Trace: true})
+	CalculatePi(5000, pi)
+
+	trace := pi.LastTrace()
+	if len(trace) < 2 {
+		t.Fatalf("Expected several traced ranges, got %d", len(trace))
+	}
+
+	workers := make(map[int]bool)
+	var terms int64
+	for _, r := range trace {
+		if r.End <= r.Start || r.Duration < 0 {
+			t.Errorf("Invalid range trace %+v", r)
+		}
+		workers[r.Worker] = true
+		terms += r.End - r.Start
+	}
+	if len(workers) < 2 {
+		t.Errorf("Expected ranges from more than one worker, got %v", workers)
+	}
+	// Both the first run and the verification run are traced
+	if _, total := pi.GetTerms(); terms != total {
+		t.Errorf("Expected traced ranges to cover all %d terms, got %d", total, terms)
+	}
+
+	// τ is traced the same way
+	tau := NewPiWithOptions(5000, Options{Trace: true})
+	if err := CalculateTau(5000, tau); err != nil {
+		t.Fatal(err)
+	}
+	if len(tau.LastTrace()) < 2 {
+		t.Errorf("Expected several traced ranges for τ, got %d", len(tau.LastTrace()))
+	}
+
+	// Without the option nothing is recorded
+	plain := NewPi(5000)
+	CalculatePi(5000, plain)
+	if trace := plain.LastTrace(); len(trace) != 0 {
+		t.Errorf("Expected no trace without Options.Trace, got %d ranges", len(trace))
+	}
+}
+
+func TestAutoAlgorithm(t *testing.T) {
+	if a := AutoAlgorithm(1_000_000); a != Chudnovsky {
+		t.Errorf("Expected Chudnovsky for a large precision, got %v", a)
+	}
+	if a := AutoAlgorithm(15); a != Spigot {
+		t.Errorf("Expected Spigot for a tiny precision, got %v", a)
+	}
+
+	defer func(orig int64) { AutoSpigotMaxPrecision = orig }(AutoSpigotMaxPrecision)
+	AutoSpigotMaxPrecision = 0
+	if a := AutoAlgorithm(15); a != Chudnovsky {
+		t.Errorf("Expected Chudnovsky with the spigot disabled, got %v", a)
+	}
+}
+
+func TestAutoAndSpigotAlgorithms(t *testing.T) {
+	for _, precision := range []int64{5, 15, 50, 300} {
+		for _, opts := range []Options{
+			{Algorithm: Auto},
+			{Algorithm: Spigot, NoHardcode: true},
+			{Algorithm: Spigot, NoHardcode: true, Rounding: RoundToNearest},
+		} {
+			reference := NewPiWithOptions(precision, Options{Rounding: opts.Rounding})
+			CalculatePi(precision, reference)
+
+			pi := NewPiWithOptions(precision, opts)
+			if err := CalculatePiContext(context.Background(), precision, pi); err != nil {
+				t.Fatalf("Precision %d %+v: %v", precision, opts, err)
+			}
+			if !reflect.DeepEqual(pi.GetDigits(int(precision)+1), reference.GetDigits(int(precision)+1)) {
+				t.Errorf("Precision %d %+v: digits differ from Chudnovsky", precision, opts)
+			}
+			if !pi.IsComplete() || pi.GetProgress() != 100 {
+				t.Errorf("Precision %d %+v: expected a complete calculation", precision, opts)
+			}
+		}
+	}
+
+	// Auto records the algorithm it resolved to
+	pi := NewPiWithOptions(15, Options{Algorithm: Auto})
+	CalculatePi(15, pi)
+	if pi.algorithm != Spigot {
+		t.Errorf("Expected Auto to resolve to Spigot at 15 digits, got %v", pi.algorithm)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	var previews [][]int
+	pi := NewPiWithOptions(2000, Options{Preview: func(digits []int) {
+		previews = append(previews, digits)
+	}})
+	CalculatePi(2000, pi)
+
+	// One preview per calculation, however many guard digit retries it takes
+	if len(previews) != 1 {
+		t.Fatalf("Expected the preview callback to fire once, got %d", len(previews))
+	}
+	for _, preview := range previews {
+		if len(preview) != PreviewDigits+1 || FormatPlain(preview) != piReference[:PreviewDigits+1] {
+			t.Errorf("Expected the first %d digits of pi, got %v", PreviewDigits+1, preview)
+		}
+	}
+
+	// Hardcoded precisions finish at once and need no preview
+	previews = nil
+	small := NewPiWithOptions(5, Options{Preview: func(digits []int) { previews = append(previews, digits) }})
+	CalculatePi(5, small)
+	if len(previews) != 0 {
+		t.Errorf("Expected no preview for a hardcoded precision, got %d", len(previews))
+	}
+}
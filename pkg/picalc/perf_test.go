@@ -0,0 +1,99 @@
+package picalc
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// perfBaselinePath holds the timing TestNoPerfRegression compares against
+var perfBaselinePath = filepath.Join("testdata", "perf_baseline.json")
+
+// perfBaseline is a recorded calculation time. Durations depend on the
+// machine, so regenerate it with PICALC_PERF=update where the test runs.
+type perfBaseline struct {
+	Precision  int64   `json:"precision"`
+	DurationMS float64 `json:"duration_ms"`
+	Tolerance  float64 `json:"tolerance"` // allowed slowdown factor, e.g. 1.5
+}
+
+// checkPerfRegression returns the slowdown of elapsed against the baseline
+// and whether it is within the baseline's tolerance
+func checkPerfRegression(base perfBaseline, elapsed time.Duration) (float64, bool) {
+	ratio := float64(elapsed.Microseconds()) / 1000 / base.DurationMS
+	return ratio, ratio <= base.Tolerance
+}
+
+// bestCalculationTime returns the fastest of runs calculations at precision,
+// which is far less noisy than a single run
+func bestCalculationTime(precision int64, runs int) time.Duration {
+	var best time.Duration
+	for i := 0; i < runs; i++ {
+		pi := NewPi(precision)
+		start := time.Now()
+		CalculatePi(precision, pi)
+		if elapsed := time.Since(start); i == 0 || elapsed < best {
+			best = elapsed
+		}
+	}
+	return best
+}
+
+// TestNoPerfRegression fails if calculating Pi has become significantly
+// slower than the stored baseline. Timings are too noisy for shared CI, so it
+// only runs with PICALC_PERF=1, or PICALC_PERF=update to record a new baseline.
+func TestNoPerfRegression(t *testing.T) {
+	mode := os.Getenv("PICALC_PERF")
+	if mode == "" {
+		t.Skip("Set PICALC_PERF=1 to compare against the timing baseline")
+	}
+
+	data, err := os.ReadFile(perfBaselinePath)
+	if err != nil {
+		t.Fatalf("Failed to read baseline: %v", err)
+	}
+	var base perfBaseline
+	if err := json.Unmarshal(data, &base); err != nil {
+		t.Fatalf("Invalid baseline: %v", err)
+	}
+
+	elapsed := bestCalculationTime(base.Precision, 3)
+
+	if mode == "update" {
+		base.DurationMS = float64(elapsed.Microseconds()) / 1000
+		data, _ := json.MarshalIndent(base, "", "  ")
+		if err := os.WriteFile(perfBaselinePath, append(data, '\n'), 0644); err != nil {
+			t.Fatalf("Failed to write baseline: %v", err)
+		}
+		t.Logf("Recorded %v for %d digits", elapsed, base.Precision)
+		return
+	}
+
+	ratio, ok := checkPerfRegression(base, elapsed)
+	t.Logf("%d digits took %v, %.2fx the baseline of %.1fms", base.Precision, elapsed, ratio, base.DurationMS)
+	if !ok {
+		t.Errorf("Calculation is %.2fx slower than the baseline, beyond the %.2fx tolerance", ratio, base.Tolerance)
+	}
+}
+
+func TestCheckPerfRegression(t *testing.T) {
+	base := perfBaseline{Precision: 1000, DurationMS: 100, Tolerance: 1.5}
+
+	tests := []struct {
+		elapsed time.Duration
+		ratio   float64
+		ok      bool
+	}{
+		{50 * time.Millisecond, 0.5, true},
+		{150 * time.Millisecond, 1.5, true},
+		{151 * time.Millisecond, 1.51, false},
+	}
+	for _, tt := range tests {
+		ratio, ok := checkPerfRegression(base, tt.elapsed)
+		if ratio != tt.ratio || ok != tt.ok {
+			t.Errorf("%v: expected %.2fx ok=%v, got %.2fx ok=%v", tt.elapsed, tt.ratio, tt.ok, ratio, ok)
+		}
+	}
+}
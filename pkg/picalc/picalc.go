@@ -1,106 +1,436 @@
 package picalc
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"iter"
 	"math"
 	"math/big"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // VERSION is the current version of the picalc package
 const VERSION = "0.1.0"
 
+// Algorithms returns the names of the algorithms the package implements
+func Algorithms() []string {
+	return []string{
+		"chudnovsky", // CalculatePiContext, CalculateTau
+		"ramanujan",  // CalculatePiContext with Options.Algorithm
+		"spigot",     // StreamPiSpigot, or CalculatePiContext with Options.Algorithm
+		"bbp",        // NthHexDigit, HexDigits
+	}
+}
+
 // Pi represents a structure for storing and synchronizing
 // computed digits of Pi
 type Pi struct {
-	digits    []int
-	mutex     sync.RWMutex
-	computed  atomic.Int64
-	precision int64
+	digits     []int
+	mutex      sync.RWMutex
+	computed   atomic.Int64 // completed series terms, or digits when streaming; see GetTerms
+	totalTerms atomic.Int64 // series terms (or digits) needed for precision
+	complete   atomic.Bool  // set once every digit is stored, cleared when a calculation starts
+	serial     atomic.Bool  // the series was summed on one goroutine for lack of GOMAXPROCS
+	precision  int64
+	elapsed    time.Duration
+	opts       Options
+	checksum   string // digest streamed during extraction, if enabled
+	timings    Timings
+	mapping    []byte // backing memory of digits for NewPiMmap, or nil
+	trace      []RangeTrace
+	ring       *digitRing // the last digits streamed, with Options.RetainDigits
+	algorithm  Algorithm  // used by the last calculation, with Auto resolved
 }
 
 // NewPi creates a new Pi calculator with specified precision
 func NewPi(precision int64) *Pi {
+	return NewPiWithOptions(precision, Options{})
+}
+
+// NewPiWithOptions creates a new Pi calculator with specified precision and options
+func NewPiWithOptions(precision int64, opts Options) *Pi {
+	if opts.RetainDigits > 0 {
+		return &Pi{
+			ring:      newDigitRing(int(min(int64(opts.RetainDigits), precision+1))),
+			precision: precision,
+			opts:      opts,
+		}
+	}
+
 	return &Pi{
 		digits:    make([]int, precision+1), // +1 for the '3' digit
 		precision: precision,
+		opts:      opts,
 	}
 }
 
-// CalculatePi calculates decimal digits of Pi using Chudnovsky algorithm
+// hardcodedPi is used for very small precisions, with enough extra digits to round the last one
+const hardcodedPi = "3.14159265358979323846"
+
+// splitRoot sums the series for calculatePiChudnovsky. Tests swap it to inject degenerate results.
+var splitRoot = binarySplitRoot
+
+// mulInt multiplies the large combine-step operands. Tests swap it to inject failures.
+var mulInt = func(z, x, y *big.Int) *big.Int {
+	return z.Mul(x, y)
+}
+
+// CalculatePi calculates decimal digits of Pi using Chudnovsky algorithm.
+// It panics if the calculation fails; use CalculatePiContext to get the error.
+// Unlike CalculatePiContext it accepts a precision other than pi's, storing
+// only the digits that fit.
 func CalculatePi(precision int64, pi *Pi) {
-	// For very small precisions, use hardcoded values
-	if precision <= 10 {
-		hardcodedPi := []int{3, 1, 4, 1, 5, 9, 2, 6, 5, 3}
+	if err := calculateDecimal(context.Background(), precision, pi, func(s string) string { return s }); err != nil {
+		panic(err)
+	}
+}
+
+// CalculatePiContext calculates decimal digits of Pi using Chudnovsky algorithm,
+// stopping early if ctx is cancelled. Panics in worker goroutines are recovered
+// and returned as errors. precision must be the one pi was created with, or
+// ErrPrecisionMismatch is returned.
+func CalculatePiContext(ctx context.Context, precision int64, pi *Pi) error {
+	if err := checkPrecision(precision, pi); err != nil {
+		return err
+	}
+	return calculateDecimal(ctx, precision, pi, func(s string) string { return s })
+}
+
+// checkPrecision rejects a precision other than the one pi was created with.
+// Fewer digits would leave stale ones behind, and more wouldn't fit.
+func checkPrecision(precision int64, pi *Pi) error {
+	if allocated := pi.currentPrecision(); precision != allocated {
+		return fmt.Errorf("%w: calculating %d digits into a pi of %d", ErrPrecisionMismatch, precision, allocated)
+	}
+	return nil
+}
+
+// calculateDecimal calculates pi to precision, passes the decimal string
+// through transform and stores the result in pi, recording the elapsed time
+// and, with Options.Trace, the trace. It is shared by π and τ.
+func calculateDecimal(ctx context.Context, precision int64, pi *Pi, transform func(string) string) error {
+	startTime := time.Now()
+	defer func() { pi.elapsed = time.Since(startTime) }()
+
+	var t *tracer
+	if pi.opts.Trace {
+		ctx, t = withTracer(ctx)
+	}
+
+	var timings Timings
+	decimalStr, err := piDecimal(ctx, precision, pi, &timings)
+	if err != nil {
+		return err
+	}
+
+	if t != nil {
 		pi.mutex.Lock()
-		for i := 0; i < len(hardcodedPi) && i < len(pi.digits); i++ {
-			pi.digits[i] = hardcodedPi[i]
-		}
+		pi.trace = t.ranges
 		pi.mutex.Unlock()
-		pi.computed.Store(pi.precision)
-		return
+	}
+
+	pi.storeDecimal(transform(decimalStr), precision, &timings, startTime)
+	return nil
+}
+
+// PiDigits calculates Pi to precision decimal places and returns the leading 3
+// followed by the fractional digits, for callers that don't need a Pi
+func PiDigits(precision int64) ([]int, error) {
+	if precision < 0 {
+		return nil, fmt.Errorf("precision must be non-negative, got %d", precision)
+	}
+
+	pi := NewPi(precision)
+	if err := CalculatePiContext(context.Background(), precision, pi); err != nil {
+		return nil, err
+	}
+	return pi.GetDigits(int(precision) + 1), nil
+}
+
+// piDecimal returns Pi as a decimal string with at least precision fractional digits
+func piDecimal(ctx context.Context, precision int64, pi *Pi, timings *Timings) (string, error) {
+	pi.complete.Store(false)
+	pi.serial.Store(false)
+
+	if pi.ring != nil {
+		return "", fmt.Errorf("pi retains only the last %d digits; use StreamPiSpigot", pi.opts.RetainDigits)
+	}
+
+	pi.algorithm = pi.opts.Algorithm
+	if pi.algorithm == Auto {
+		pi.algorithm = AutoAlgorithm(precision)
+	}
+
+	if precision <= 10 && !pi.opts.NoHardcode {
+		// For very small precisions, use hardcoded values
+		pi.computed.Store(0)
+		pi.totalTerms.Store(1)
+		return hardcodedPi, nil
+	}
+
+	if pi.algorithm == Spigot {
+		return spigotDecimal(ctx, precision, pi)
+	}
+
+	if pi.opts.Preview != nil {
+		preview := NewPi(PreviewDigits)
+		if err := StreamPiSpigot(ctx, PreviewDigits, preview, io.Discard); err != nil {
+			return "", err
+		}
+		pi.opts.Preview(preview.GetDigits(PreviewDigits + 1))
 	}
 
 	// Calculate Pi using fixed precision algorithm
-	decimalStr := calculatePiChudnovsky(precision)
+	return calculatePiStable(ctx, precision, pi, timings)
+}
+
+// storeDecimal rounds decimalStr (e.g. "3.14159...") to precision places and
+// stores its digits, then marks the calculation as completed
+func (p *Pi) storeDecimal(decimalStr string, precision int64, timings *Timings, startTime time.Time) {
+	extractStart := time.Now()
+
+	// Round the last requested digit from the guard digits
+	decimalStr = roundDecimal(decimalStr, int(precision), p.opts.Rounding)
 
 	// Extract the digits
-	pi.mutex.Lock()
-	// First digit is 3
-	pi.digits[0] = 3
+	p.mutex.Lock()
+	// First digit is the integer part, 3 for Pi
+	p.digits[0] = int(decimalStr[0] - '0')
 
 	// Extract the decimal part (skip the "3." at the beginning)
 	start := 2 // Skip "3."
-	for i := 1; i <= int(precision) && i < len(pi.digits) && start < len(decimalStr); i++ {
+	for i := 1; i <= int(precision) && i < len(p.digits) && start < len(decimalStr); i++ {
 		if decimalStr[start] >= '0' && decimalStr[start] <= '9' {
-			pi.digits[i] = int(decimalStr[start] - '0')
+			p.digits[i] = int(decimalStr[start] - '0')
 		}
 		start++
 	}
-	pi.mutex.Unlock()
+
+	// Hash the decimal string the digits were extracted from, once at
+	// extraction, rather than formatting the stored digits back into text
+	p.checksum = ""
+	if p.opts.StreamChecksum {
+		h := sha256.New()
+		io.WriteString(h, decimalStr[:start])
+		p.checksum = hex.EncodeToString(h.Sum(nil))
+	}
+
+	if p.opts.RecordTimings {
+		timings.Extraction += time.Since(extractStart)
+		timings.Total = time.Since(startTime)
+		p.timings = *timings
+	}
+	p.mutex.Unlock()
 
 	// Mark as completed
-	pi.computed.Store(pi.precision)
+	p.computed.Store(p.totalTerms.Load())
+	p.complete.Store(true)
+}
+
+// initialGuardDigits is the number of extra digits computed beyond the requested precision
+var initialGuardDigits int64 = 10
+
+// maxGuardRetries caps how many times calculatePiStable adds guard digits
+const maxGuardRetries = 5
+
+// ErrDivisionByZero is returned when binary splitting yields a series sum
+// that the final division cannot use
+var ErrDivisionByZero = errors.New("division by zero")
+
+// ErrPrecisionMismatch is returned when a calculation is asked for a
+// different precision than its Pi was created with
+var ErrPrecisionMismatch = errors.New("precision does not match pi")
+
+// ErrUnstableDigits is returned when the requested digits keep changing as guard digits are added
+var ErrUnstableDigits = errors.New("digits did not stabilize")
+
+// calculatePiStable calculates pi with initialGuardDigits guard digits and, unless
+// Options.Fast is set, recomputes with 10 more until the requested digits agree
+// between two runs, so every returned digit is correct
+func calculatePiStable(ctx context.Context, precision int64, pi *Pi, timings *Timings) (string, error) {
+	guard := initialGuardDigits
+
+	terms, calculate := chudnovskyTerms, calculatePiChudnovsky
+	if pi.algorithm == Ramanujan {
+		terms, calculate = ramanujanTerms, calculatePiRamanujan
+	}
+
+	// Account for the verification run up front so progress doesn't jump back
+	pi.computed.Store(0)
+	total := terms(precision + guard)
+	if !pi.opts.Fast {
+		total += terms(precision + guard + 10)
+	}
+	pi.totalTerms.Store(total)
+
+	decimalStr, err := calculate(ctx, precision, guard, pi, timings)
+	if err != nil || pi.opts.Fast {
+		return decimalStr, err
+	}
+
+	end := 2 + int(precision) // "3." and the requested digits
+	for retry := 0; retry < maxGuardRetries; retry++ {
+		guard += 10
+		if retry > 0 {
+			pi.totalTerms.Add(terms(precision + guard))
+		}
+
+		next, err := calculate(ctx, precision, guard, pi, timings)
+		if err != nil {
+			return "", err
+		}
+
+		if len(next) >= end && len(decimalStr) >= end && next[:end] == decimalStr[:end] {
+			return next, nil
+		}
+		decimalStr = next
+	}
+
+	return "", fmt.Errorf("%w after %d guard digits at precision %d", ErrUnstableDigits, guard, precision)
+}
+
+// chudnovskyConstants returns the constants A, B and 640320³/24 of the Chudnovsky series
+func chudnovskyConstants() (A, B, C3_24 *big.Int) {
+	return big.NewInt(13591409), big.NewInt(545140134), big.NewInt(640320 * 640320 * 640320 / 24)
 }
 
-// calculatePiChudnovsky calculates pi to specified precision using Chudnovsky algorithm
-func calculatePiChudnovsky(precision int64) string {
-	// Calculate number of terms needed (each term gives ~14.18 digits)
-	terms := int64(float64(precision)/14.18) + 2
+// DigitsPerChudnovskyTerm is how many decimal digits each Chudnovsky term adds,
+// log10(640320³ / (24 * 6 * 2 * 6)) = log10(151931373056000)
+const DigitsPerChudnovskyTerm = 14.181647462725477
+
+// chudnovskyTerms returns the number of series terms needed for digits decimal digits,
+// rounding up so a partial term is never dropped
+func chudnovskyTerms(digits int64) int64 {
+	return int64(math.Ceil(float64(digits)/DigitsPerChudnovskyTerm)) + 1
+}
+
+// calculatePiChudnovsky calculates pi to precision plus guard digits using Chudnovsky algorithm,
+// counting completed terms on pi for progress reporting and the time spent in each phase on timings
+func calculatePiChudnovsky(ctx context.Context, precision, guard int64, pi *Pi, timings *Timings) (decimal string, err error) {
+	// A panic while splitting in this goroutine surfaces as an error too
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("pi calculation panicked: %v", r)
+		}
+	}()
+
+	// Calculate number of terms needed for the requested and guard digits
+	digits := precision + guard
+	terms := chudnovskyTerms(digits)
 
 	// Set up constants for Chudnovsky algorithm
-	A := big.NewInt(13591409)
-	B := big.NewInt(545140134)
-	C3_24 := big.NewInt(640320 * 640320 * 640320 / 24)
+	A, B, C3_24 := chudnovskyConstants()
 
 	// Set precision for big.Float operations
-	floatPrec := uint(int(math.Ceil(math.Log2(10)*float64(precision))) + 100)
+	floatPrec := floatPrecision(digits)
 
 	// Use binary splitting to calculate the sum
 	// P, Q, R are as defined in the Chudnovsky paper
 	var Q, R *big.Int
+	phaseStart := time.Now()
 
-	// For small calculations, use direct approach
-	if precision < 100 {
-		_, Q, R = binarySplitSerial(0, terms, A, B, C3_24)
-	} else {
-		// For larger calculations, use parallel approach
-		_, Q, R = binarySplitParallel(0, terms, A, B, C3_24)
+	// For small calculations, use direct approach; for larger ones, the parallel
+	// approach unless GOMAXPROCS leaves nothing to run the workers on
+	parallel := precision >= 100
+	if procs := runtime.GOMAXPROCS(0); parallel && procs < 2 {
+		parallel = false
+		if !pi.serial.Swap(true) && pi.opts.Logger != nil {
+			pi.opts.Logger.Printf("running single-threaded: GOMAXPROCS=%d", procs)
+		}
+	}
+
+	// The square root doesn't depend on the series, so with cores to spare it
+	// is computed while the series is summed
+	sqrtCh := make(chan *big.Float, 1)
+	if parallel {
+		go func() {
+			sqrtCh <- sqrt10005(floatPrec)
+		}()
+	}
+
+	Q, R, err = splitRoot(ctx, 0, terms, A, B, C3_24, &pi.computed, parallel)
+	if err != nil {
+		return "", err
+	}
+	timings.BinarySplit += lap(&phaseStart)
+
+	// The serial split doesn't check ctx, so don't start a division that
+	// would only be abandoned
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	// Both R/Q and C/(R/Q) below divide by zero if either is zero
+	if Q.Sign() == 0 || R.Sign() == 0 {
+		return "", fmt.Errorf("final division at precision %d: zero series term sum: %w", precision, ErrDivisionByZero)
 	}
 
 	// Final calculation Pi = (426880 * sqrt(10005)) / (R/Q)
 	// Convert to big.Float for division and square root
-	sqrtArg := new(big.Float).SetPrec(floatPrec)
-	sqrtArg.SetInt64(10005)
-
-	sqrt10005 := new(big.Float).SetPrec(floatPrec)
-	sqrt10005.Sqrt(sqrtArg)
+	var sqrtValue *big.Float
+	if parallel {
+		sqrtValue = <-sqrtCh
+	} else {
+		sqrtValue = sqrt10005(floatPrec)
+	}
+	timings.Sqrt += lap(&phaseStart)
 
 	C := new(big.Float).SetPrec(floatPrec)
 	C.SetInt64(426880)
-	C.Mul(C, sqrt10005)
+	C.Mul(C, sqrtValue)
+
+	// The division and decimal conversion can't be interrupted and take minutes
+	// at high precision, so run them in a goroutine that is abandoned on cancel
+	result := make(chan finalResult, 1)
+	go func() {
+		result <- finalDivision(C, Q, R, floatPrec, digits)
+	}()
+
+	select {
+	case res := <-result:
+		if res.err != nil {
+			return "", res.err
+		}
+		timings.Division += res.division
+		timings.Extraction += res.extraction
+		return res.decimal, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// quoFloat performs the final division of Pi = C / sum. Tests swap it to stall the division.
+var quoFloat = func(z, x, y *big.Float) *big.Float {
+	return z.Quo(x, y)
+}
+
+// finalResult is the outcome of finalDivision and how long each phase took
+type finalResult struct {
+	decimal              string
+	division, extraction time.Duration
+	err                  error
+}
+
+// finalDivision computes Pi = C / (R/Q) at floatPrec bits and formats it with digits decimal places
+func finalDivision(C *big.Float, Q, R *big.Int, floatPrec uint, digits int64) (res finalResult) {
+	// This runs in its own goroutine, out of reach of the caller's recover
+	defer func() {
+		if r := recover(); r != nil {
+			res.err = fmt.Errorf("final division panicked: %v", r)
+		}
+	}()
+	phaseStart := time.Now()
 
 	// R/Q
 	sum := new(big.Float).SetPrec(floatPrec)
@@ -113,168 +443,764 @@ func calculatePiChudnovsky(precision int64) string {
 	sum.Quo(sumR, sumQ)
 
 	// Pi = C / sum
-	pi := new(big.Float).SetPrec(floatPrec)
-	pi.Quo(C, sum)
+	piVal := new(big.Float).SetPrec(floatPrec)
+	quoFloat(piVal, C, sum)
+	res.division = lap(&phaseStart)
 
 	// Return as string with enough precision
-	return pi.Text('f', int(precision)+10)
+	res.decimal = piVal.Text('f', int(digits))
+	res.extraction = lap(&phaseStart)
+
+	return res
+}
+
+// lap returns the time elapsed since *start and resets it to now
+func lap(start *time.Time) time.Duration {
+	now := time.Now()
+	d := now.Sub(*start)
+	*start = now
+	return d
 }
 
-// binarySplitSerial computes the Chudnovsky series using binary splitting (serial version)
-func binarySplitSerial(a, b int64, A, B, C3_24 *big.Int) (*big.Int, *big.Int, *big.Int) {
+// binarySplitSerial computes the Chudnovsky series using binary splitting (serial version).
+// Each completed term is added to done when it is non-nil.
+func binarySplitSerial(a, b int64, A, B, C3_24 *big.Int, done *atomic.Int64) (*big.Int, *big.Int, *big.Int) {
+	if b < a {
+		panic(fmt.Sprintf("binary split: invalid term range [%d, %d)", a, b))
+	}
+
+	// Empty range: the identity for combining, P = Q = 1, R = 0
+	if a == b {
+		return big.NewInt(1), big.NewInt(1), big.NewInt(0)
+	}
+
 	// Base case: compute a single term
 	if b-a == 1 {
-		var P, Q, R *big.Int
-
-		if a == 0 {
-			// First term
-			P = big.NewInt(1)
-			Q = big.NewInt(1)
-			R = new(big.Int).Set(A) // 13591409
-		} else {
-			// P(a) = (6a-5)(2a-1)(6a-1)
-			P = big.NewInt(6*a - 5)
-			P = P.Mul(P, big.NewInt(2*a-1))
-			P = P.Mul(P, big.NewInt(6*a-1))
-
-			// Q(a) = a^3 * C3_24
-			Q = big.NewInt(a)
-			Q = Q.Mul(Q, big.NewInt(a))
-			Q = Q.Mul(Q, big.NewInt(a))
-			Q = Q.Mul(Q, C3_24)
-
-			// R(a) = P(a) * (A + B*a)
-			term := new(big.Int).Mul(B, big.NewInt(a))
-			term = term.Add(term, A)
-			R = new(big.Int).Mul(P, term)
-
-			// Alternate sign: (-1)^a
-			if a%2 == 1 {
-				R = R.Neg(R)
-			}
+		P, Q, R := chudnovskyTerm(a, A, B, C3_24)
+		if done != nil {
+			done.Add(1)
 		}
-
 		return P, Q, R
 	}
 
 	// Recursive case: split the range
 	m := (a + b) / 2
-	P1, Q1, R1 := binarySplitSerial(a, m, A, B, C3_24)
-	P2, Q2, R2 := binarySplitSerial(m, b, A, B, C3_24)
+	P1, Q1, R1 := binarySplitSerial(a, m, A, B, C3_24, done)
+	P2, Q2, R2 := binarySplitSerial(m, b, A, B, C3_24, done)
+
+	return combinePQR(P1, Q1, R1, P2, Q2, R2)
+}
 
-	// Combine the results
+// chudnovskyTerm returns P, Q and R for the single term a. The coefficients
+// are computed in big.Int arithmetic, since 6a and a³ overflow int64 long
+// before a does.
+func chudnovskyTerm(a int64, A, B, C3_24 *big.Int) (P, Q, R *big.Int) {
+	if a == 0 {
+		// First term
+		return big.NewInt(1), big.NewInt(1), new(big.Int).Set(A) // 13591409
+	}
+
+	bigA := big.NewInt(a)
+	linear := func(k, c int64) *big.Int {
+		x := big.NewInt(k)
+		x.Mul(x, bigA)
+		return x.Add(x, big.NewInt(c))
+	}
+
+	// P(a) = (6a-5)(2a-1)(6a-1)
+	P = linear(6, -5)
+	P = P.Mul(P, linear(2, -1))
+	P = P.Mul(P, linear(6, -1))
+
+	// Q(a) = a^3 * C3_24
+	Q = new(big.Int).Mul(bigA, bigA)
+	Q = Q.Mul(Q, bigA)
+	Q = Q.Mul(Q, C3_24)
+
+	// R(a) = P(a) * (A + B*a)
+	term := new(big.Int).Mul(B, bigA)
+	term = term.Add(term, A)
+	R = new(big.Int).Mul(P, term)
+
+	// Alternate sign: (-1)^a
+	if a%2 == 1 {
+		R = R.Neg(R)
+	}
+
+	return P, Q, R
+}
+
+// combinePQR combines the binary splitting results of adjacent ranges
+// [a, m) and [m, b) into the result for [a, b). It holds for any series
+// whose terms are a ratio P/Q of the previous term times a factor folded into R.
+// The arithmetic is exact, so the result doesn't depend on where a range is
+// split, and the serial and parallel splits agree bit for bit.
+func combinePQR(P1, Q1, R1, P2, Q2, R2 *big.Int) (*big.Int, *big.Int, *big.Int) {
 	// P = P1 * P2
-	P := new(big.Int).Mul(P1, P2)
+	P := mulInt(new(big.Int), P1, P2)
 
 	// Q = Q1 * Q2
-	Q := new(big.Int).Mul(Q1, Q2)
+	Q := mulInt(new(big.Int), Q1, Q2)
 
 	// R = R1 * Q2 + P1 * R2
-	R1Q2 := new(big.Int).Mul(R1, Q2)
-	P1R2 := new(big.Int).Mul(P1, R2)
+	R1Q2 := mulInt(new(big.Int), R1, Q2)
+	P1R2 := mulInt(new(big.Int), P1, R2)
 	R := new(big.Int).Add(R1Q2, P1R2)
 
 	return P, Q, R
 }
 
-// binarySplitParallel computes the Chudnovsky series using binary splitting (parallel version)
-func binarySplitParallel(a, b int64, A, B, C3_24 *big.Int) (*big.Int, *big.Int, *big.Int) {
+// binarySplitRoot computes Q and R for the whole series [a, b). It splits once
+// and combines the halves without forming P, which the final formula never
+// uses, saving the largest multiplication and the memory for its result.
+func binarySplitRoot(ctx context.Context, a, b int64, A, B, C3_24 *big.Int, done *atomic.Int64, parallel bool) (*big.Int, *big.Int, error) {
+	if b-a <= 1 {
+		var Q, R *big.Int
+		traceRange(ctx, a, b, func() {
+			_, Q, R = binarySplitSerial(a, b, A, B, C3_24, done)
+		})
+		return Q, R, nil
+	}
+
+	m := (a + b) / 2
+	var left, right splitResult
+	if parallel {
+		// Calculate left half in parallel, as binarySplitParallel does
+		leftCh := make(chan splitResult, 1)
+		leftCtx := spawnWorker(ctx)
+		go func() {
+			leftCh <- binarySplitRecover(leftCtx, a, m, A, B, C3_24, done)
+		}()
+		right = binarySplitRecover(ctx, m, b, A, B, C3_24, done)
+		left = <-leftCh
+
+		if right.err != nil {
+			return nil, nil, right.err
+		}
+		if left.err != nil {
+			return nil, nil, left.err
+		}
+	} else {
+		traceRange(ctx, a, m, func() {
+			left.P, left.Q, left.R = binarySplitSerial(a, m, A, B, C3_24, done)
+		})
+		traceRange(ctx, m, b, func() {
+			right.P, right.Q, right.R = binarySplitSerial(m, b, A, B, C3_24, done)
+		})
+	}
+
+	// Q = Q1 * Q2
+	Q := mulInt(new(big.Int), left.Q, right.Q)
+
+	// R = R1 * Q2 + P1 * R2
+	R1Q2 := mulInt(new(big.Int), left.R, right.Q)
+	P1R2 := mulInt(new(big.Int), left.P, right.R)
+	R := new(big.Int).Add(R1Q2, P1R2)
+
+	return Q, R, nil
+}
+
+// splitResult carries a binary split result, or the error that stopped it,
+// back from a worker goroutine
+type splitResult struct {
+	P, Q, R *big.Int
+	err     error
+}
+
+// binarySplitRecover runs binarySplitParallel over [a, b), converting a panic
+// into an error on the result
+func binarySplitRecover(ctx context.Context, a, b int64, A, B, C3_24 *big.Int, done *atomic.Int64) (res splitResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			res.err = fmt.Errorf("binary split of terms [%d, %d) panicked: %v", a, b, r)
+		}
+	}()
+
+	res.P, res.Q, res.R, res.err = binarySplitParallel(ctx, a, b, A, B, C3_24, done)
+	return res
+}
+
+// binarySplitParallel computes the Chudnovsky series using binary splitting (parallel version).
+// It stops with ctx.Err() once ctx is cancelled, and a panic in a worker goroutine
+// is returned as an error.
+func binarySplitParallel(ctx context.Context, a, b int64, A, B, C3_24 *big.Int, done *atomic.Int64) (*big.Int, *big.Int, *big.Int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+	if b < a {
+		return nil, nil, nil, fmt.Errorf("binary split: invalid term range [%d, %d)", a, b)
+	}
+
 	// For small ranges, use serial version
 	if b-a <= 100 {
-		return binarySplitSerial(a, b, A, B, C3_24)
+		var P, Q, R *big.Int
+		traceRange(ctx, a, b, func() {
+			P, Q, R = binarySplitSerial(a, b, A, B, C3_24, done)
+		})
+		return P, Q, R, nil
 	}
 
 	// Split the range
 	m := (a + b) / 2
 
-	// Use goroutines for parallel computation
-	var P1, Q1, R1, P2, Q2, R2 *big.Int
-	var wg sync.WaitGroup
-
-	// Calculate left half in parallel
-	wg.Add(1)
+	// Calculate left half in parallel, delivering the result over a channel
+	left := make(chan splitResult, 1)
+	leftCtx := spawnWorker(ctx)
 	go func() {
-		defer wg.Done()
-		P1, Q1, R1 = binarySplitParallel(a, m, A, B, C3_24)
+		left <- binarySplitRecover(leftCtx, a, m, A, B, C3_24, done)
 	}()
 
 	// Calculate right half in this goroutine
-	P2, Q2, R2 = binarySplitParallel(m, b, A, B, C3_24)
+	right := binarySplitRecover(ctx, m, b, A, B, C3_24, done)
 
-	// Wait for left half to complete
-	wg.Wait()
+	// Wait for left half to complete, even if the right half failed,
+	// so no worker outlives the call
+	lres := <-left
+	if right.err != nil {
+		return nil, nil, nil, right.err
+	}
+	if lres.err != nil {
+		return nil, nil, nil, lres.err
+	}
+	P, Q, R := combinePQR(lres.P, lres.Q, lres.R, right.P, right.Q, right.R)
+	return P, Q, R, nil
+}
 
-	// Combine the results
-	// P = P1 * P2
-	P := new(big.Int).Mul(P1, P2)
+// GetDigits returns the first n decimal digits of Pi. An n beyond the
+// computed digits returns them all, and n <= 0 returns an empty slice, so
+// callers never need to clamp n themselves.
+func (p *Pi) GetDigits(n int) []int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
 
-	// Q = Q1 * Q2
-	Q := new(big.Int).Mul(Q1, Q2)
+	n = min(max(n, 0), len(p.digits))
+	result := make([]int, n)
+	copy(result, p.digits[:n])
+	return result
+}
 
-	// R = R1 * Q2 + P1 * R2
-	R1Q2 := new(big.Int).Mul(R1, Q2)
-	P1R2 := new(big.Int).Mul(P1, R2)
-	R := new(big.Int).Add(R1Q2, P1R2)
+// numDigits returns how many digits p holds, including the leading 3. Like
+// every read of p.digits it holds the lock, as Truncate replaces the slice.
+func (p *Pi) numDigits() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return len(p.digits)
+}
 
-	return P, Q, R
+// currentPrecision returns the precision p holds digits for, under the lock
+// as Truncate lowers it
+func (p *Pi) currentPrecision() int64 {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.precision
 }
 
-// GetDigits returns the first n decimal digits of Pi
-func (p *Pi) GetDigits(n int) []int {
-	if n > len(p.digits) {
-		n = len(p.digits)
+// Truncate keeps only the leading 3 and the first n decimal places and sets
+// the precision to n, e.g. to trim a Pi before caching it. Heap digits are
+// copied to a new slice so the rest can be freed. An n at or beyond the
+// current precision changes nothing, and a negative n is treated as 0.
+func (p *Pi) Truncate(n int) {
+	n = max(n, 0)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if n+1 >= len(p.digits) {
+		return
+	}
+
+	if p.mapping != nil {
+		p.digits = p.digits[:n+1]
+	} else {
+		digits := make([]int, n+1)
+		copy(digits, p.digits)
+		p.digits = digits
+	}
+	p.precision = int64(n)
+	p.checksum = "" // the streamed digest covered the removed digits
+}
+
+// GetDigit returns digit i of Pi, where 0 is the leading 3, without copying
+// any others. It fails if i is out of range or the calculation hasn't completed.
+func (p *Pi) GetDigit(i int) (int, error) {
+	if !p.IsComplete() {
+		return 0, fmt.Errorf("digit %d is not computed yet", i)
 	}
 
 	p.mutex.RLock()
-	result := make([]int, n)
-	copy(result, p.digits[:n])
+	defer p.mutex.RUnlock()
+
+	if i < 0 || i >= len(p.digits) {
+		return 0, fmt.Errorf("digit %d is out of range [0, %d)", i, len(p.digits))
+	}
+	return p.digits[i], nil
+}
+
+// GetDigitsFrom returns count decimal digits of Pi starting at position start,
+// where position 0 is the leading 3. It fails if the range is out of bounds.
+func (p *Pi) GetDigitsFrom(start, count int) ([]int, error) {
+	if start < 0 || count < 0 {
+		return nil, fmt.Errorf("invalid digit range: start %d, count %d", start, count)
+	}
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if start+count > len(p.digits) {
+		return nil, fmt.Errorf("digit range [%d, %d) exceeds the %d computed digits", start, start+count, len(p.digits))
+	}
+	result := make([]int, count)
+	copy(result, p.digits[start:start+count])
+	return result, nil
+}
+
+// ScaledInt returns the leading 3 and the first decimalPlaces computed digits
+// as the integer Pi * 10^decimalPlaces, e.g. 314159 for 5 places, for use
+// with fixed-point arithmetic elsewhere.
+// It fails if decimalPlaces exceeds the computed precision or the
+// calculation hasn't completed.
+func (p *Pi) ScaledInt(decimalPlaces int) (*big.Int, error) {
+	if !p.IsComplete() {
+		return nil, fmt.Errorf("pi is not computed yet")
+	}
+
+	p.mutex.RLock()
+	if decimalPlaces < 0 || decimalPlaces >= len(p.digits) {
+		p.mutex.RUnlock()
+		return nil, fmt.Errorf("%d decimal places is out of range [0, %d]", decimalPlaces, len(p.digits)-1)
+	}
+	buf := make([]byte, decimalPlaces+1)
+	for i, d := range p.digits[:decimalPlaces+1] {
+		buf[i] = '0' + byte(d)
+	}
 	p.mutex.RUnlock()
 
+	scaled, _ := new(big.Int).SetString(string(buf), 10)
+	return scaled, nil
+}
+
+// ScaledValue returns every computed digit as the integer Pi * 10^scale,
+// along with the scale, which is the precision. It is ScaledInt at the full
+// precision, for callers formatting or doing arithmetic on the whole value.
+func (p *Pi) ScaledValue() (*big.Int, int64, error) {
+	places := p.numDigits() - 1
+	scaled, err := p.ScaledInt(places)
+	if err != nil {
+		return nil, 0, err
+	}
+	return scaled, int64(places), nil
+}
+
+// Rat returns the leading 3 and the first n decimal places as the exact
+// rational ScaledInt(n) / 10^n, e.g. 314159/100000 for 5 places, for exact
+// arithmetic. An n beyond the computed precision is clamped to it and a
+// negative n to 0. It returns nil if the calculation hasn't completed.
+func (p *Pi) Rat(n int) *big.Rat {
+	n = min(max(n, 0), p.numDigits()-1)
+
+	scaled, err := p.ScaledInt(n)
+	if err != nil {
+		return nil
+	}
+	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+	return new(big.Rat).SetFrac(scaled, denom)
+}
+
+// Float64 returns the computed digits as the nearest float64, using at most
+// the 17 significant digits a float64 can distinguish. A precision of 0 gives
+// exactly 3. It fails rather than return NaN or ±Inf, if the calculation
+// hasn't completed or no digits are held.
+func (p *Pi) Float64() (float64, error) {
+	if !p.IsComplete() {
+		return 0, fmt.Errorf("pi is not computed yet")
+	}
+
+	p.mutex.RLock()
+	n := min(len(p.digits), 17)
+	buf := make([]byte, 0, n+1)
+	for i, d := range p.digits[:n] {
+		buf = append(buf, '0'+byte(d))
+		if i == 0 && n > 1 {
+			buf = append(buf, '.')
+		}
+	}
+	p.mutex.RUnlock()
+
+	if n == 0 {
+		return 0, fmt.Errorf("no digits to convert")
+	}
+
+	f, err := strconv.ParseFloat(string(buf), 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("digits %q are not a finite float64", buf)
+	}
+	return f, nil
+}
+
+// GetDigitsStride returns every stride-th of the first n decimal digits of Pi,
+// i.e. the digits at positions 0, stride, 2*stride, ... below n.
+// A stride of 1 or less returns the same digits as GetDigits.
+func (p *Pi) GetDigitsStride(n, stride int) []int {
+	if stride <= 1 {
+		return p.GetDigits(n)
+	}
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	n = min(max(n, 0), len(p.digits))
+	result := make([]int, 0, (n+stride-1)/stride)
+	for i := 0; i < n; i += stride {
+		result = append(result, p.digits[i])
+	}
 	return result
 }
 
-// GetProgress returns the percentage of computation completed
-func (p *Pi) GetProgress() float64 {
-	computed := p.computed.Load()
-	divisor := float64(p.precision) / 14.0
-	if divisor <= 0 {
-		divisor = 1
+// Digits returns an iterator over the first n decimal digits of Pi.
+// The read lock is held only while each batch is copied out, so callers
+// may break early or do slow work per digit without blocking writers.
+func (p *Pi) Digits(n int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		const batchSize = 1000
+		batch := make([]int, 0, batchSize)
+		for i := 0; i < n; i += batchSize {
+			// A Truncate between batches ends the sequence early
+			p.mutex.RLock()
+			end := min(i+batchSize, n, len(p.digits))
+			if i >= end {
+				p.mutex.RUnlock()
+				return
+			}
+			batch = append(batch[:0], p.digits[i:end]...)
+			p.mutex.RUnlock()
+
+			for _, d := range batch {
+				if !yield(d) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Duration returns how long the last calculation took
+func (p *Pi) Duration() time.Duration {
+	return p.elapsed
+}
+
+// GetTerms returns the completed and total units of work of the current
+// calculation: series terms, or digits for StreamPiSpigot.
+//
+// Each term is counted exactly once, so completed <= total. Both only grow
+// during a calculation and completed is reset first when one starts, so
+// reading completed first can only see it ahead as a reused Pi restarts a
+// smaller calculation, and it is capped at total for that case.
+func (p *Pi) GetTerms() (completed, total int64) {
+	completed, total = p.computed.Load(), p.totalTerms.Load()
+	return min(completed, total), total
+}
+
+// SingleThreaded reports whether the last calculation summed the Chudnovsky
+// series on one goroutine because GOMAXPROCS was below 2, as in containers
+// limited to one CPU. Precisions below 100 are always summed serially and
+// don't count.
+func (p *Pi) SingleThreaded() bool {
+	return p.serial.Load()
+}
+
+// IsComplete reports whether the last calculation finished and stored every
+// digit. It is false for a new Pi, while a calculation runs, and after one
+// fails or is cancelled, so servers can poll background calculations with it.
+func (p *Pi) IsComplete() bool {
+	return p.complete.Load()
+}
+
+// Fraction returns the fraction of the computation completed, from 0 to 1,
+// measured as completed series terms over the total needed, or emitted digits
+// over the total for StreamPiSpigot. It reaches 1 when the last term is summed,
+// before the final division, so use IsComplete to tell when digits are ready.
+func (p *Pi) Fraction() float64 {
+	total := p.totalTerms.Load()
+	if total <= 0 {
+		return 0.0
 	}
-	progress := float64(computed) / divisor * 100.0
+	fraction := float64(p.computed.Load()) / float64(total)
 
-	// Ensure progress is between 0 and 99
-	if progress > 99.0 {
-		progress = 99.0
+	// Ensure the fraction is between 0 and 1
+	if fraction > 1.0 {
+		fraction = 1.0
 	}
-	if progress < 0.0 {
-		progress = 0.0
+	if fraction < 0.0 {
+		fraction = 0.0
 	}
 
-	return progress
+	return fraction
+}
+
+// GetProgress returns the percentage of computation completed, Fraction * 100
+func (p *Pi) GetProgress() float64 {
+	return p.Fraction() * 100.0
 }
 
-// WriteDigitsToFile writes Pi digits to a file
+// WriteDigitsToFile writes Pi digits to a file. The digits go to a temporary
+// file first which is renamed into place on success, so an interrupted write
+// never leaves a truncated file at filename.
 func WriteDigitsToFile(digits []int, filename string) error {
-	f, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("error creating file: %v", err)
+	return WriteDigitsToFileWithOptions(digits, filename, TextOptions{})
+}
+
+// WriteDigitsToFileBatched is like WriteDigitsToFile but converts and writes
+// batchSize digits at a time. Larger batches mean fewer writes at the cost of
+// a larger buffer.
+func WriteDigitsToFileBatched(digits []int, filename string, batchSize int) error {
+	if batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+	return WriteDigitsToFileWithOptions(digits, filename, TextOptions{BatchSize: batchSize})
+}
+
+// WriteDigitsToFileWithOptions is like WriteDigitsToFile with control over the text format
+func WriteDigitsToFileWithOptions(digits []int, filename string, opts TextOptions) error {
+	return WriteDigitsToFileContext(context.Background(), digits, filename, opts)
+}
+
+// WriteDigitsToFileContext is like WriteDigitsToFileWithOptions but stops
+// between batches once ctx is cancelled. The temporary file is removed and
+// filename is left untouched, and the error wraps ctx.Err().
+func WriteDigitsToFileContext(ctx context.Context, digits []int, filename string, opts TextOptions) error {
+	return writeFileAtomic(filename, func(f io.Writer) error {
+		return writeDigitsText(ctx, f, digits, opts)
+	})
+}
+
+// WriteDigitsToFileParallel writes the same file as WriteDigitsToFile, with
+// workers goroutines converting and writing disjoint ranges of it at once,
+// for very large outputs on storage fast enough to keep up. A workers value
+// of 0 or less uses GOMAXPROCS.
+func WriteDigitsToFileParallel(digits []int, filename string, workers int) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		if len(digits) == 0 {
+			return nil
+		}
+
+		// writeFileAtomic always writes to an *os.File
+		f := w.(*os.File)
+
+		// "3." then one byte per fractional digit
+		if err := f.Truncate(int64(len(digits)) + 1); err != nil {
+			return err
+		}
+		if _, err := f.WriteAt([]byte{'0' + byte(digits[0]), '.'}, 0); err != nil {
+			return err
+		}
+
+		frac := digits[1:]
+		chunk := (len(frac) + workers - 1) / workers
+		errs := make([]error, workers)
+		var wg sync.WaitGroup
+
+		for worker := 0; worker < workers && worker*chunk < len(frac); worker++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				start, end := worker*chunk, min((worker+1)*chunk, len(frac))
+				errs[worker] = writeDigitsAt(f, frac[start:end], int64(start)+2)
+			}()
+		}
+
+		wg.Wait()
+		return errors.Join(errs...)
+	})
+}
+
+// writeDigitsAt writes digits to f as text starting at byte offset off,
+// converting them a batch at a time
+func writeDigitsAt(f io.WriterAt, digits []int, off int64) error {
+	buf := make([]byte, 0, min(len(digits), defaultBatchSize(len(digits))))
+	for i := 0; i < len(digits); i += cap(buf) {
+		buf = buf[:0]
+		for _, d := range digits[i:min(i+cap(buf), len(digits))] {
+			buf = append(buf, '0'+byte(d))
+		}
+		if _, err := f.WriteAt(buf, off+int64(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TextOptions controls how digits are written as text
+type TextOptions struct {
+	// FractionalOnly omits the leading "3."
+	FractionalOnly bool
+
+	// BatchSize is the number of digits converted per write,
+	// or 0 to scale it with the number of digits
+	BatchSize int
+
+	// LineWidth starts a new line after every LineWidth fractional digits,
+	// or 0 to write them all on one line
+	LineWidth int
+
+	// CRLF ends lines with "\r\n" instead of "\n", for Windows tools
+	CRLF bool
+
+	// DecimalSep is written after the leading 3 instead of ".", e.g. ','
+	// for "3,14159", or 0 for "." (see ParseDecimalSep)
+	DecimalSep byte
+}
+
+// WriteDigitsText streams digits to w as text, "3." followed by the
+// fractional digits unless opts says otherwise. It stops at and returns
+// the first write error.
+func WriteDigitsText(w io.Writer, digits []int, opts TextOptions) error {
+	return writeDigitsText(context.Background(), w, digits, opts)
+}
+
+// WriteDigitsContext writes digits to w as WriteDigitsText does with default
+// options, checking ctx between batches so writing billions of digits can be
+// interrupted. It returns ctx.Err() once ctx is cancelled, with some of the
+// digits possibly already written.
+func WriteDigitsContext(ctx context.Context, w io.Writer, digits []int) error {
+	return writeDigitsText(ctx, w, digits, TextOptions{})
+}
+
+// writeDigitsText implements WriteDigitsText, returning ctx.Err() before any
+// batch once ctx is cancelled
+func writeDigitsText(ctx context.Context, w io.Writer, digits []int, opts TextOptions) error {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize(len(digits))
 	}
-	defer f.Close()
 
 	// Write the initial 3.
-	f.WriteString("3.")
+	if len(digits) > 0 && !opts.FractionalOnly {
+		sep := opts.DecimalSep
+		if sep == 0 {
+			sep = '.'
+		}
+		if _, err := w.Write([]byte{'0' + byte(digits[0]), sep}); err != nil {
+			return err
+		}
+	}
 
 	// Write digits in batches to avoid memory spikes
-	const batchSize = 1000
+	buf := make([]byte, 0, batchSize)
 	for i := 1; i < len(digits); i += batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		end := i + batchSize
 		if end > len(digits) {
 			end = len(digits)
 		}
 
+		buf = buf[:0]
 		for j := i; j < end; j++ {
-			fmt.Fprint(f, digits[j])
+			if opts.LineWidth > 0 && j > 1 && (j-1)%opts.LineWidth == 0 {
+				if opts.CRLF {
+					buf = append(buf, '\r')
+				}
+				buf = append(buf, '\n')
+			}
+			buf = append(buf, '0'+byte(digits[j]))
+		}
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// defaultBatchSize scales the write batch with the output size,
+// between 1000 digits and 1 MiB
+func defaultBatchSize(n int) int {
+	const minBatch, maxBatch = 1000, 1 << 20
+
+	size := n / 16
+	if size < minBatch {
+		return minBatch
+	}
+	if size > maxBatch {
+		return maxBatch
+	}
+	return size
+}
+
+// ErrOutputIsDirectory is returned when digits would be written to a path
+// that is a directory
+var ErrOutputIsDirectory = errors.New("output path is a directory")
+
+// CheckOutputPath reports whether a digits file could be written to filename,
+// so a bad path can be rejected before a long calculation rather than after
+// it. The error wraps ErrOutputIsDirectory if filename is a directory, and
+// names the directory if it is missing or can't be written to.
+func CheckOutputPath(filename string) error {
+	f, err := createTempFor(filename)
+	if err != nil {
+		return err
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return nil
+}
+
+// createTempFor creates the temporary file writeFileAtomic renames over
+// filename, returning the errors CheckOutputPath describes
+func createTempFor(filename string) (*os.File, error) {
+	if info, err := os.Stat(filename); err == nil && info.IsDir() {
+		return nil, fmt.Errorf("%w: %s", ErrOutputIsDirectory, filename)
+	}
+
+	dir := filepath.Dir(filename)
+	f, err := os.CreateTemp(dir, "."+filepath.Base(filename)+".tmp*")
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("output directory does not exist: %s", dir)
+	case errors.Is(err, fs.ErrPermission):
+		return nil, fmt.Errorf("output directory is not writable: %s", dir)
+	case err != nil:
+		return nil, fmt.Errorf("error creating file: %v", err)
+	}
+	return f, nil
+}
+
+// writeFileAtomic calls write with a temporary file next to filename and
+// renames it over filename once write and close succeed. On any error the
+// temporary file is removed and filename is left untouched.
+func writeFileAtomic(filename string, write func(io.Writer) error) (err error) {
+	f, err := createTempFor(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
 		}
+	}()
+
+	if err := write(f); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error closing file: %w", err)
+	}
+
+	// CreateTemp uses 0600; match the permissions os.Create would give
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return fmt.Errorf("error setting file mode: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), filename); err != nil {
+		return fmt.Errorf("error renaming file: %w", err)
 	}
 
 	return nil
@@ -2,11 +2,20 @@ package picalc
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"math/big"
 	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -53,23 +62,930 @@ func TestPiCalculation(t *testing.T) {
 	})
 }
 
+func TestPiDigits(t *testing.T) {
+	digits, err := PiDigits(10)
+	if err != nil {
+		t.Fatalf("PiDigits failed: %v", err)
+	}
+
+	expected := []int{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5}
+	if !reflect.DeepEqual(digits, expected) {
+		t.Errorf("Digits mismatch.\nExpected: %v\nGot: %v", expected, digits)
+	}
+
+	if _, err := PiDigits(-1); err == nil {
+		t.Error("Expected an error for a negative precision")
+	}
+}
+
+func TestDigitsIterator(t *testing.T) {
+	pi := NewPi(2500)
+	CalculatePi(2500, pi)
+
+	// Spans several batches
+	var sum, count int
+	for d := range pi.Digits(2500) {
+		sum += d
+		count++
+	}
+
+	expected := 0
+	for _, d := range pi.GetDigits(2500) {
+		expected += d
+	}
+
+	if count != 2500 {
+		t.Errorf("Iterator yielded %d digits, expected 2500", count)
+	}
+	if sum != expected {
+		t.Errorf("Digit sum mismatch.\nExpected: %d\nGot: %d", expected, sum)
+	}
+
+	// Breaking early must release the lock so writers can proceed
+	for d := range pi.Digits(2500) {
+		if d != 3 {
+			t.Errorf("First digit should be 3, got %d", d)
+		}
+		break
+	}
+	pi.mutex.Lock()
+	pi.mutex.Unlock()
+}
+
+func TestGetDigit(t *testing.T) {
+	pi := NewPi(20)
+	if _, err := pi.GetDigit(0); err == nil {
+		t.Error("Expected an error before the calculation")
+	}
+
+	CalculatePi(20, pi)
+	for i, expected := range []int{3, 1, 4, 1, 5, 9} {
+		if d, err := pi.GetDigit(i); err != nil || d != expected {
+			t.Errorf("Digit %d: expected %d, got %d (%v)", i, expected, d, err)
+		}
+	}
+	if d, err := pi.GetDigit(20); err != nil || d != 6 {
+		t.Errorf("Last digit: expected 6, got %d (%v)", d, err)
+	}
+
+	for _, i := range []int{-1, 21, 1000} {
+		if _, err := pi.GetDigit(i); err == nil {
+			t.Errorf("Expected an error for index %d", i)
+		}
+	}
+}
+
+func TestGetDigitsFrom(t *testing.T) {
+	pi := NewPi(20)
+	CalculatePi(20, pi)
+
+	// Positions 5-9 of 3.1415926535...
+	digits, err := pi.GetDigitsFrom(5, 5)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []int{9, 2, 6, 5, 3}; !reflect.DeepEqual(digits, expected) {
+		t.Errorf("Expected %v, got %v", expected, digits)
+	}
+
+	// The range may end exactly at the last digit
+	if digits, err := pi.GetDigitsFrom(19, 2); err != nil || len(digits) != 2 {
+		t.Errorf("Expected the last two digits, got %v (%v)", digits, err)
+	}
+
+	for _, r := range [][2]int{{-1, 5}, {5, -1}, {20, 2}, {100, 1}} {
+		if _, err := pi.GetDigitsFrom(r[0], r[1]); err == nil {
+			t.Errorf("Expected an error for start %d, count %d", r[0], r[1])
+		}
+	}
+}
+
+func TestGetDigitsStride(t *testing.T) {
+	pi := NewPi(10)
+	CalculatePi(10, pi)
+
+	// Positions 0, 2, 4, 6, 8 of 3141592653
+	expected := []int{3, 4, 5, 2, 5}
+	if got := pi.GetDigitsStride(10, 2); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Stride 2 mismatch.\nExpected: %v\nGot: %v", expected, got)
+	}
+
+	// A partial last stride still includes its first position
+	if got := pi.GetDigitsStride(10, 3); !reflect.DeepEqual(got, []int{3, 1, 2, 3}) {
+		t.Errorf("Stride 3 mismatch, got %v", got)
+	}
+
+	if got := pi.GetDigitsStride(10, 1); !reflect.DeepEqual(got, pi.GetDigits(10)) {
+		t.Errorf("Stride 1 should match GetDigits, got %v", got)
+	}
+	if got := pi.GetDigitsStride(0, 2); len(got) != 0 {
+		t.Errorf("Expected no digits for n=0, got %v", got)
+	}
+}
+
+func TestBinarySplitRanges(t *testing.T) {
+	A := big.NewInt(13591409)
+	B := big.NewInt(545140134)
+	C3_24 := big.NewInt(640320 * 640320 * 640320 / 24)
+
+	// Empty ranges yield the combine identity
+	for _, a := range []int64{0, 7} {
+		P, Q, R := binarySplitSerial(a, a, A, B, C3_24, nil)
+		if P.Int64() != 1 || Q.Int64() != 1 || R.Sign() != 0 {
+			t.Errorf("Empty range [%d, %d) should be {1, 1, 0}, got {%v, %v, %v}", a, a, P, Q, R)
+		}
+	}
+
+	// A single term is its own base case
+	P, Q, R := binarySplitSerial(0, 1, A, B, C3_24, nil)
+	if P.Int64() != 1 || Q.Int64() != 1 || R.Cmp(A) != 0 {
+		t.Errorf("Range [0, 1) should be {1, 1, %v}, got {%v, %v, %v}", A, P, Q, R)
+	}
+
+	// Combining with an empty range changes nothing
+	P1, Q1, R1 := binarySplitSerial(0, 5, A, B, C3_24, nil)
+	P2, Q2, R2, err := binarySplitParallel(context.Background(), 5, 5, A, B, C3_24, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error for empty parallel range: %v", err)
+	}
+	if new(big.Int).Mul(P1, P2).Cmp(P1) != 0 || new(big.Int).Mul(Q1, Q2).Cmp(Q1) != 0 ||
+		new(big.Int).Add(new(big.Int).Mul(R1, Q2), new(big.Int).Mul(P1, R2)).Cmp(R1) != 0 {
+		t.Error("Combining with an empty range should be the identity")
+	}
+
+	// Reversed ranges are rejected
+	if _, _, _, err := binarySplitParallel(context.Background(), 5, 4, A, B, C3_24, nil); err == nil {
+		t.Error("Expected an error for a reversed range")
+	}
+}
+
+func TestBinarySplitRoot(t *testing.T) {
+	A := big.NewInt(13591409)
+	B := big.NewInt(545140134)
+	C3_24 := big.NewInt(640320 * 640320 * 640320 / 24)
+
+	// Skipping P at the root must not change Q or R
+	for _, terms := range []int64{0, 1, 2, 7, 250} {
+		for _, parallel := range []bool{false, true} {
+			_, expectedQ, expectedR := binarySplitSerial(0, terms, A, B, C3_24, nil)
+
+			Q, R, err := binarySplitRoot(context.Background(), 0, terms, A, B, C3_24, nil, parallel)
+			if err != nil {
+				t.Fatalf("Unexpected error for %d terms: %v", terms, err)
+			}
+			if Q.Cmp(expectedQ) != 0 || R.Cmp(expectedR) != 0 {
+				t.Errorf("Root combine of %d terms (parallel %v) differs from the full split", terms, parallel)
+			}
+		}
+	}
+}
+
+func TestPrecisionMismatch(t *testing.T) {
+	for _, tt := range [][2]int64{{5, 10}, {20, 10}, {200, 100}} {
+		precision, allocated := tt[0], tt[1]
+		pi := NewPi(allocated)
+		err := CalculatePiContext(context.Background(), precision, pi)
+		if !errors.Is(err, ErrPrecisionMismatch) {
+			t.Errorf("Calculating %d digits into a pi of %d: expected ErrPrecisionMismatch, got %v", precision, allocated, err)
+		}
+		if pi.IsComplete() {
+			t.Errorf("Calculating %d digits into a pi of %d: expected an incomplete pi", precision, allocated)
+		}
+	}
+
+	if err := CalculateTau(5, NewPi(10)); !errors.Is(err, ErrPrecisionMismatch) {
+		t.Errorf("Expected ErrPrecisionMismatch from CalculateTau, got %v", err)
+	}
+
+	// CalculatePi keeps accepting a different precision, storing what fits
+	pi := NewPi(10)
+	CalculatePi(5, pi)
+	if got := pi.GetString(6); got != "3.14159" {
+		t.Errorf("CalculatePi(5, NewPi(10)) = %q, want 3.14159", got)
+	}
+}
+
+func TestParallelEqualsSerial(t *testing.T) {
+	A, B, C3_24 := chudnovskyConstants()
+
+	// The parallel split must be bit-identical to the serial one, including
+	// ranges that don't start at 0 and split below the serial cutoff unevenly
+	for _, r := range [][2]int64{{0, 500}, {0, 101}, {137, 500}, {499, 500}} {
+		a, b := r[0], r[1]
+		var serialDone, parallelDone atomic.Int64
+		P1, Q1, R1 := binarySplitSerial(a, b, A, B, C3_24, &serialDone)
+		P2, Q2, R2, err := binarySplitParallel(context.Background(), a, b, A, B, C3_24, &parallelDone)
+		if err != nil {
+			t.Fatalf("Unexpected error for [%d, %d): %v", a, b, err)
+		}
+		if P1.Cmp(P2) != 0 || Q1.Cmp(Q2) != 0 || R1.Cmp(R2) != 0 {
+			t.Errorf("Parallel split of [%d, %d) differs from the serial split", a, b)
+		}
+		if serialDone.Load() != b-a || parallelDone.Load() != b-a {
+			t.Errorf("Expected %d terms counted for [%d, %d), got %d serial and %d parallel",
+				b-a, a, b, serialDone.Load(), parallelDone.Load())
+		}
+	}
+}
+
+func TestWorkerPanicRecovery(t *testing.T) {
+	orig := mulInt
+	defer func() { mulInt = orig }()
+
+	mulInt = func(z, x, y *big.Int) *big.Int {
+		panic("injected multiplication failure")
+	}
+
+	// Large enough to take the parallel path
+	pi := NewPi(3000)
+	err := CalculatePiContext(context.Background(), 3000, pi)
+	if err == nil {
+		t.Fatal("Expected an error from a panicking worker")
+	}
+	if !strings.Contains(err.Error(), "injected multiplication failure") {
+		t.Errorf("Error should carry the panic value, got: %v", err)
+	}
+}
+
+func TestDegenerateSeriesSum(t *testing.T) {
+	orig := splitRoot
+	defer func() { splitRoot = orig }()
+
+	splitRoot = func(ctx context.Context, a, b int64, A, B, C3_24 *big.Int, done *atomic.Int64, parallel bool) (*big.Int, *big.Int, error) {
+		return big.NewInt(1), big.NewInt(0), nil
+	}
+
+	pi := NewPi(500)
+	err := CalculatePiContext(context.Background(), 500, pi)
+	if !errors.Is(err, ErrDivisionByZero) {
+		t.Fatalf("Expected ErrDivisionByZero, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "final division at precision 500") {
+		t.Errorf("Error should name the phase and precision, got: %v", err)
+	}
+}
+
+func TestCalculatePiContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pi := NewPi(3000)
+	if err := CalculatePiContext(ctx, 3000, pi); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got: %v", err)
+	}
+}
+
+func TestCancelDuringDivision(t *testing.T) {
+	orig := quoFloat
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	defer func() {
+		close(release)
+		quoFloat = orig
+	}()
+
+	// Stall the final division until the test ends
+	quoFloat = func(z, x, y *big.Float) *big.Float {
+		close(entered)
+		<-release
+		return orig(z, x, y)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- CalculatePiContext(ctx, 1000, NewPi(1000))
+	}()
+
+	<-entered
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("CalculatePiContext did not return after cancellation during the division")
+	}
+}
+
+func TestGuardDigitRetry(t *testing.T) {
+	orig := initialGuardDigits
+	defer func() { initialGuardDigits = orig }()
+
+	// Without guard digits the 12th place rounds: 3.141592653589|79 -> ...590
+	initialGuardDigits = 0
+	expected := []int{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5, 8, 9}
+
+	fast := NewPiWithOptions(12, Options{Fast: true})
+	CalculatePi(12, fast)
+	if reflect.DeepEqual(fast.GetDigits(13), expected) {
+		t.Fatal("Expected the fast path to get the last digit wrong without guard digits")
+	}
+
+	// Verification notices the unstable digit and retries with more guard digits
+	pi := NewPi(12)
+	CalculatePi(12, pi)
+	if digits := pi.GetDigits(13); !reflect.DeepEqual(digits, expected) {
+		t.Errorf("Verified digits mismatch.\nExpected: %v\nGot: %v", expected, digits)
+	}
+	if progress := pi.GetProgress(); progress != 100.0 {
+		t.Errorf("Progress should be 100%% after retries, got %f", progress)
+	}
+}
+
+func TestTermsNearMultiplesOf14(t *testing.T) {
+	orig := initialGuardDigits
+	defer func() { initialGuardDigits = orig }()
+
+	// Without guard digits or verification, any missing term shows in the last digits
+	initialGuardDigits = 0
+
+	for _, k := range []int64{1, 2, 7, 10, 35, 70} {
+		for _, precision := range []int64{14*k - 1, 14 * k, 14*k + 1} {
+			if terms := chudnovskyTerms(precision); float64(terms-1)*DigitsPerChudnovskyTerm < float64(precision) {
+				t.Errorf("Precision %d: %d terms cannot provide enough digits", precision, terms)
+			}
+
+			if precision <= 10 {
+				continue // hardcoded
+			}
+
+			pi := NewPiWithOptions(precision, Options{Fast: true})
+			CalculatePi(precision, pi)
+
+			// The last digit may be off by rounding from the missing guard digits
+			if _, err := pi.ValidateAgainstReference(int(precision) - 1); err != nil {
+				t.Errorf("Precision %d: %v", precision, err)
+			}
+		}
+	}
+}
+
 func TestProgressTracking(t *testing.T) {
 	// Test progress reporting
 	pi := NewPi(100)
 
+	// No terms known yet
+	if progress := pi.GetProgress(); progress != 0.0 {
+		t.Errorf("Progress should be 0 before calculation starts, got: %f", progress)
+	}
+
 	// Simulate partial completion
-	pi.computed.Store(50)
+	pi.totalTerms.Store(10)
+	pi.computed.Store(5)
 
 	progress := pi.GetProgress()
-	if progress < 1.0 || progress > 99.0 {
-		t.Errorf("Progress should be between 1 and 99, got: %f", progress)
+	if progress != 50.0 {
+		t.Errorf("Progress should be 50%%, got: %f", progress)
+	}
+	if completed, total := pi.GetTerms(); completed != 5 || total != 10 {
+		t.Errorf("Expected 5/10 terms, got %d/%d", completed, total)
 	}
 
-	// Test 100% completion (should be capped at 99%)
-	pi.computed.Store(pi.precision / 14 * 2) // Double the expected completion
+	// Test overshoot (should be capped at 100%)
+	pi.computed.Store(20) // Double the expected completion
 	progress = pi.GetProgress()
-	if progress != 99.0 {
-		t.Errorf("Progress should be capped at 99%%, got: %f", progress)
+	if progress != 100.0 {
+		t.Errorf("Progress should be capped at 100%%, got: %f", progress)
+	}
+	if completed, total := pi.GetTerms(); completed != 10 || total != 10 {
+		t.Errorf("Expected completed terms capped at 10/10, got %d/%d", completed, total)
+	}
+
+	// A finished calculation reports every term as completed
+	pi = NewPi(100)
+	CalculatePi(100, pi)
+	if progress := pi.GetProgress(); progress != 100.0 {
+		t.Errorf("Progress should be 100%% after calculation, got: %f", progress)
+	}
+	if pi.computed.Load() != pi.totalTerms.Load() {
+		t.Errorf("Completed terms %d should equal total terms %d", pi.computed.Load(), pi.totalTerms.Load())
+	}
+}
+
+// TestConcurrentProgressAccounting runs calculations of every kind at once
+// while polling their progress. Run it with -race to check the accounting is
+// free of data races as well as exact.
+func TestConcurrentProgressAccounting(t *testing.T) {
+	// Parallel splitting needs more than one P
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	// Concurrent splits sharing a counter count each term exactly once
+	A, B, C3_24 := chudnovskyConstants()
+	terms := chudnovskyTerms(5000)
+	var done atomic.Int64
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			binarySplitRoot(context.Background(), 0, terms, A, B, C3_24, &done, true)
+		}()
+	}
+	wg.Wait()
+	if done.Load() != 4*terms {
+		t.Errorf("Expected %d terms counted, got %d", 4*terms, done.Load())
+	}
+
+	// computed <= totalTerms at every read, and equal once finished
+	watch := func(pi *Pi, stop <-chan struct{}) {
+		defer wg.Done()
+		for {
+			if completed, total := pi.GetTerms(); completed > total || completed < 0 {
+				t.Errorf("Read %d of %d terms completed", completed, total)
+				return
+			}
+			if f := pi.Fraction(); f < 0 || f > 1 {
+				t.Errorf("Fraction %v out of range", f)
+				return
+			}
+			select {
+			case <-stop:
+				return
+			default:
+				runtime.Gosched()
+			}
+		}
+	}
+
+	for _, opts := range []Options{
+		{},
+		{Fast: true},
+		{Algorithm: Ramanujan},
+		{Algorithm: Spigot},
+		{NoHardcode: true},
+	} {
+		for _, precision := range []int64{5, 300, 3000} {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				pi := NewPiWithOptions(precision, opts)
+				stop := make(chan struct{})
+				go watch(pi, stop)
+				defer close(stop)
+
+				if err := CalculatePiContext(context.Background(), precision, pi); err != nil {
+					t.Errorf("%+v at %d digits: %v", opts, precision, err)
+					return
+				}
+				if completed, total := pi.GetTerms(); completed != total || total == 0 {
+					t.Errorf("%+v at %d digits: finished with %d of %d terms", opts, precision, completed, total)
+				}
+			}()
+		}
+	}
+
+	// A reused Pi restarting with fewer terms is watched throughout
+	wg.Add(1)
+	reused := NewPi(3000)
+	stop := make(chan struct{})
+	go watch(reused, stop)
+	for range 3 {
+		CalculatePi(3000, reused)
+		StreamPiSpigot(context.Background(), 20, reused, io.Discard)
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestSingleThreadedFallback(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	orig := splitRoot
+	defer func() { splitRoot = orig }()
+
+	var parallelRuns, serialRuns int
+	splitRoot = func(ctx context.Context, a, b int64, A, B, C3_24 *big.Int, done *atomic.Int64, parallel bool) (*big.Int, *big.Int, error) {
+		if parallel {
+			parallelRuns++
+		} else {
+			serialRuns++
+		}
+		return orig(ctx, a, b, A, B, C3_24, done, parallel)
+	}
+
+	var logs bytes.Buffer
+	opts := Options{Logger: log.New(&logs, "", 0)}
+
+	runtime.GOMAXPROCS(1)
+	pi := NewPiWithOptions(500, opts)
+	CalculatePi(500, pi)
+	if !pi.SingleThreaded() || parallelRuns != 0 || serialRuns == 0 {
+		t.Errorf("Expected only serial runs with GOMAXPROCS=1, got %d parallel and %d serial", parallelRuns, serialRuns)
+	}
+	// Logged once even though the verification run sums the series again
+	if logs.String() != "running single-threaded: GOMAXPROCS=1\n" {
+		t.Errorf("Unexpected log output: %q", logs.String())
+	}
+
+	runtime.GOMAXPROCS(2)
+	parallelRuns, serialRuns = 0, 0
+	logs.Reset()
+	CalculatePi(500, pi)
+	if pi.SingleThreaded() || serialRuns != 0 || logs.Len() != 0 {
+		t.Errorf("Expected parallel runs with GOMAXPROCS=2, got %d serial, log %q", serialRuns, logs.String())
+	}
+}
+
+func TestIsComplete(t *testing.T) {
+	pi := NewPi(100)
+	if pi.IsComplete() {
+		t.Error("A new Pi should not be complete")
+	}
+
+	// Every term summed is not enough; the digits must be stored
+	pi.totalTerms.Store(10)
+	pi.computed.Store(10)
+	if pi.IsComplete() {
+		t.Error("Pi should not be complete before its digits are stored")
+	}
+
+	CalculatePi(100, pi)
+	if !pi.IsComplete() {
+		t.Error("Pi should be complete after CalculatePi returns")
+	}
+
+	// A cancelled recalculation is no longer complete
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := CalculatePiContext(ctx, 100, pi); err == nil {
+		t.Fatal("Expected an error from a cancelled calculation")
+	}
+	if pi.IsComplete() {
+		t.Error("Pi should not be complete after a cancelled calculation")
+	}
+}
+
+func TestFraction(t *testing.T) {
+	pi := NewPi(100)
+	tests := []struct {
+		computed, total int64
+		expected        float64
+	}{
+		{0, 0, 0},
+		{0, 10, 0},
+		{5, 10, 0.5},
+		{20, 10, 1},
+		{-5, 10, 0},
+	}
+
+	for _, tt := range tests {
+		pi.computed.Store(tt.computed)
+		pi.totalTerms.Store(tt.total)
+		if f := pi.Fraction(); f != tt.expected || f < 0 || f > 1 {
+			t.Errorf("%d/%d terms: expected fraction %v, got %v", tt.computed, tt.total, tt.expected, f)
+		}
+	}
+
+	CalculatePi(100, pi)
+	if f := pi.Fraction(); f != 1 {
+		t.Errorf("Expected fraction 1 after calculation, got %v", f)
+	}
+}
+
+func TestScaledInt(t *testing.T) {
+	pi := NewPi(50)
+	if _, err := pi.ScaledInt(5); err == nil {
+		t.Error("Expected an error before calculation")
+	}
+	CalculatePi(50, pi)
+
+	tests := map[int]string{
+		0:  "3",
+		5:  "314159",
+		50: "314159265358979323846264338327950288419716939937510",
+	}
+	for places, expected := range tests {
+		scaled, err := pi.ScaledInt(places)
+		if err != nil {
+			t.Fatalf("ScaledInt(%d): unexpected error: %v", places, err)
+		}
+		if scaled.String() != expected {
+			t.Errorf("ScaledInt(%d): expected %s, got %s", places, expected, scaled)
+		}
+	}
+
+	for _, places := range []int{-1, 51} {
+		if _, err := pi.ScaledInt(places); err == nil {
+			t.Errorf("ScaledInt(%d): expected an out of range error", places)
+		}
+	}
+}
+
+func TestScaledValue(t *testing.T) {
+	pi := NewPi(1000)
+	if _, _, err := pi.ScaledValue(); err == nil {
+		t.Error("Expected an error before calculation")
+	}
+	CalculatePi(1000, pi)
+
+	scaled, scale, err := pi.ScaledValue()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if scale != 1000 {
+		t.Errorf("Expected scale 1000, got %d", scale)
+	}
+
+	// Dividing out the scale approximates pi
+	value, _ := new(big.Float).Quo(new(big.Float).SetInt(scaled),
+		new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(scale), nil))).Float64()
+	if math.Abs(value-math.Pi) > 1e-15 {
+		t.Errorf("Expected about %v, got %v", math.Pi, value)
+	}
+
+	// Its decimal digits are the stored ones
+	if scaled.String() != FormatPlain(pi.GetDigits(1001)) {
+		t.Error("Scaled value digits differ from GetDigits")
+	}
+}
+
+func TestRat(t *testing.T) {
+	pi := NewPi(50)
+	if r := pi.Rat(5); r != nil {
+		t.Errorf("Expected nil before calculation, got %v", r)
+	}
+	CalculatePi(50, pi)
+
+	if r := pi.Rat(5); r == nil || r.Cmp(big.NewRat(314159, 100000)) != 0 {
+		t.Errorf("Expected 314159/100000, got %v", r)
+	}
+	if r := pi.Rat(0); r == nil || r.Cmp(big.NewRat(3, 1)) != 0 {
+		t.Errorf("Expected 3, got %v", r)
+	}
+
+	// Beyond the precision clamps to every computed digit
+	scaled, _ := pi.ScaledInt(50)
+	expected := new(big.Rat).SetFrac(scaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(50), nil))
+	if r := pi.Rat(80); r == nil || r.Cmp(expected) != 0 {
+		t.Errorf("Expected all 50 places, got %v", r)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	pi := NewPiWithOptions(100, Options{StreamChecksum: true})
+	CalculatePi(100, pi)
+	full := pi.GetDigits(101)
+
+	pi.Truncate(20)
+	if got := pi.GetDigits(101); !reflect.DeepEqual(got, full[:21]) {
+		t.Errorf("Expected the first 21 digits, got %v", got)
+	}
+	if pi.precision != 20 || cap(pi.digits) != 21 {
+		t.Errorf("Expected precision 20 with 21 digits held, got %d with capacity %d", pi.precision, cap(pi.digits))
+	}
+	if pi.Checksum() != Checksum(full[:21]) {
+		t.Error("Checksum should cover only the remaining digits")
+	}
+
+	// Growing is not possible and a negative n keeps the 3
+	pi.Truncate(50)
+	if len(pi.GetDigits(101)) != 21 {
+		t.Error("Truncate beyond the precision should change nothing")
+	}
+	pi.Truncate(-1)
+	if got := pi.GetDigits(101); !reflect.DeepEqual(got, []int{3}) || pi.precision != 0 {
+		t.Errorf("Expected [3] at precision 0, got %v at %d", got, pi.precision)
+	}
+}
+
+func TestTruncateWhileReading(t *testing.T) {
+	pi := NewPi(3000)
+	CalculatePi(3000, pi)
+
+	// A reader opened before Truncate stops at the new end
+	r := NewDigitReader(pi, 3001)
+	pi.Truncate(5)
+	buf := make([]byte, 100)
+	n, err := r.Read(buf)
+	if !errors.Is(err, io.ErrUnexpectedEOF) || string(buf[:n]) != "3.14159" {
+		t.Errorf("Expected 3.14159 then io.ErrUnexpectedEOF, got %q, %v", buf[:n], err)
+	}
+
+	// As does an iterator truncated between batches
+	pi = NewPi(3000)
+	CalculatePi(3000, pi)
+	count := 0
+	for range pi.Digits(3001) {
+		if count++; count == 1 {
+			pi.Truncate(1500)
+		}
+	}
+	if count != 1501 {
+		t.Errorf("Expected the iterator to stop at 1501 digits, got %d", count)
+	}
+
+	// Every accessor races Truncate safely under -race
+	pi = NewPi(3000)
+	CalculatePi(3000, pi)
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				pi.GetDigits(3001)
+				pi.GetDigitsFrom(0, 10)
+				pi.GetDigitsStride(3001, 3)
+				pi.GetString(3001)
+				pi.ScaledValue()
+				pi.Rat(3000)
+				io.Copy(io.Discard, NewDigitReader(pi, 3001))
+				for range pi.Digits(3001) {
+				}
+			}
+		}()
+	}
+	for n := 3000; n >= 0; n -= 100 {
+		pi.Truncate(n)
+	}
+	wg.Wait()
+}
+
+func TestTruncateWhileWritingManifest(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(2))
+
+	pi := NewPi(3000)
+	CalculatePi(3000, pi)
+	path := filepath.Join(t.TempDir(), "pi.txt")
+
+	done := make(chan error)
+	go func() {
+		for range 20 {
+			if err := WriteDigitsWithManifest(pi, 3001, path); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	for n := 3000; n >= 0; n -= 100 {
+		pi.Truncate(n)
+		runtime.Gosched()
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Failed to write digits with manifest: %v", err)
+	}
+}
+
+func TestFloat64(t *testing.T) {
+	pi := NewPi(50)
+	if _, err := pi.Float64(); err == nil {
+		t.Error("Expected an error before calculation")
+	}
+	CalculatePi(50, pi)
+	if f, err := pi.Float64(); err != nil || f != math.Pi {
+		t.Errorf("Expected math.Pi, got %v (%v)", f, err)
+	}
+
+	// A Pi streamed into a ring holds no digits to convert
+	ring := NewPiWithOptions(5, Options{RetainDigits: 1})
+	StreamPiSpigot(context.Background(), 5, ring, io.Discard)
+	if f, err := ring.Float64(); err == nil {
+		t.Errorf("Expected an error for a Pi without leading digits, got %v", f)
+	}
+}
+
+func TestPrecisionBoundaries(t *testing.T) {
+	tests := []struct {
+		precision int64
+		float     float64
+		scaled    string
+	}{
+		{0, 3, "3"},
+		{1, 3.1, "31"},
+	}
+
+	for _, tt := range tests {
+		for _, opts := range []Options{{}, {NoHardcode: true}, {Fast: true, NoHardcode: true}} {
+			pi := NewPiWithOptions(tt.precision, opts)
+			if err := CalculatePiContext(context.Background(), tt.precision, pi); err != nil {
+				t.Fatalf("Precision %d %+v: %v", tt.precision, opts, err)
+			}
+
+			f, err := pi.Float64()
+			if err != nil || f != tt.float || math.IsNaN(f) || math.IsInf(f, 0) {
+				t.Errorf("Precision %d %+v: Float64 = %v (%v), expected %v", tt.precision, opts, f, err, tt.float)
+			}
+			if scaled, err := pi.ScaledInt(int(tt.precision)); err != nil || scaled.String() != tt.scaled {
+				t.Errorf("Precision %d %+v: ScaledInt = %v (%v), expected %s", tt.precision, opts, scaled, err, tt.scaled)
+			}
+			if digits := pi.GetDigits(int(tt.precision) + 5); len(digits) != int(tt.precision)+1 {
+				t.Errorf("Precision %d %+v: GetDigits returned %d digits", tt.precision, opts, len(digits))
+			}
+			if r := pi.ReliableDigits(); r < 0 || r > int(tt.precision)+1 {
+				t.Errorf("Precision %d %+v: ReliableDigits %d out of range", tt.precision, opts, r)
+			}
+			if p := pi.GetProgress(); p != 100 {
+				t.Errorf("Precision %d %+v: progress %v, expected 100", tt.precision, opts, p)
+			}
+		}
+	}
+}
+
+func TestChudnovskyTermLargeIndex(t *testing.T) {
+	// 6a overflows int64 for this a
+	const a = int64(1) << 61
+	A, B, C3_24 := chudnovskyConstants()
+	P, Q, R := chudnovskyTerm(a, A, B, C3_24)
+
+	bigA := big.NewInt(a)
+	linear := func(k, c int64) *big.Int {
+		x := new(big.Int).Mul(big.NewInt(k), bigA)
+		return x.Add(x, big.NewInt(c))
+	}
+
+	expectedP := new(big.Int).Mul(linear(6, -5), linear(2, -1))
+	expectedP.Mul(expectedP, linear(6, -1))
+	expectedQ := new(big.Int).Exp(bigA, big.NewInt(3), nil)
+	expectedQ.Mul(expectedQ, C3_24)
+	expectedR := new(big.Int).Mul(expectedP, linear(545140134, 13591409))
+
+	if P.Cmp(expectedP) != 0 {
+		t.Errorf("P mismatch: expected %s, got %s", expectedP, P)
+	}
+	if Q.Cmp(expectedQ) != 0 {
+		t.Errorf("Q mismatch: expected %s, got %s", expectedQ, Q)
+	}
+	if R.Cmp(expectedR) != 0 {
+		t.Errorf("R mismatch: expected %s, got %s", expectedR, R)
+	}
+	if R.Sign() <= 0 {
+		t.Error("Expected a positive R for an even term")
+	}
+}
+
+func TestGetDigitsBounds(t *testing.T) {
+	pi := NewPi(20)
+	CalculatePi(20, pi)
+
+	tests := []struct {
+		n        int
+		expected int
+	}{
+		{-1, 0},
+		{0, 0},
+		{1, 1},
+		{21 + 10, 21},
+	}
+
+	for _, tt := range tests {
+		digits := pi.GetDigits(tt.n)
+		if digits == nil || len(digits) != tt.expected {
+			t.Errorf("GetDigits(%d): expected %d digits, got %v", tt.n, tt.expected, digits)
+		}
+		if len(digits) > 0 && digits[0] != 3 {
+			t.Errorf("GetDigits(%d): expected a leading 3, got %v", tt.n, digits)
+		}
+	}
+}
+
+func TestWriteDigitsToFileParallel(t *testing.T) {
+	pi := NewPi(1000)
+	CalculatePi(1000, pi)
+	dir := t.TempDir()
+
+	for _, n := range []int{0, 1, 2, 7, 1001} {
+		digits := pi.GetDigits(n)
+		serial := filepath.Join(dir, "serial.txt")
+		if err := WriteDigitsToFile(digits, serial); err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := os.ReadFile(serial)
+
+		for _, workers := range []int{0, 1, 3, 8, 2000} {
+			path := filepath.Join(dir, "parallel.txt")
+			if err := WriteDigitsToFileParallel(digits, path, workers); err != nil {
+				t.Fatalf("%d digits, %d workers: unexpected error: %v", n, workers, err)
+			}
+			got, _ := os.ReadFile(path)
+			if !bytes.Equal(got, expected) {
+				t.Errorf("%d digits, %d workers: output differs from the serial writer", n, workers)
+			}
+		}
+	}
+}
+
+func TestBitsForDigits(t *testing.T) {
+	tests := map[int64]int64{
+		0:       0,
+		1:       4,
+		100:     333,
+		1000000: 3321929,
+	}
+	for digits, expected := range tests {
+		if bits := BitsForDigits(digits); bits != expected {
+			t.Errorf("BitsForDigits(%d): expected %d, got %d", digits, expected, bits)
+		}
+	}
+
+	// Each term adds log10(151931373056000) digits
+	if math.Abs(DigitsPerChudnovskyTerm-math.Log10(151931373056000)) > 1e-12 {
+		t.Errorf("Unexpected digits per term %v", DigitsPerChudnovskyTerm)
+	}
+	if terms := chudnovskyTerms(1000); terms != 72 {
+		t.Errorf("Expected 72 terms for 1000 digits, got %d", terms)
 	}
 }
 
@@ -95,6 +1011,277 @@ func TestFileIO(t *testing.T) {
 	os.Remove(tempFile)
 }
 
+func TestFileWriteFailureLeavesNoFile(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "pi.txt")
+
+	err := writeFileAtomic(target, func(w io.Writer) error {
+		io.WriteString(w, "3.14")
+		return errors.New("disk full")
+	})
+	if err == nil {
+		t.Fatal("Expected the write error to be returned")
+	}
+
+	// Neither the destination nor the temporary file may remain
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read temp dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no files after a failed write, found %d (%s)", len(entries), entries[0].Name())
+	}
+
+	// A previous good file is left untouched
+	if err := WriteDigitsToFile([]int{3, 1, 4}, target); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	writeFileAtomic(target, func(w io.Writer) error {
+		return errors.New("interrupted")
+	})
+	if content, _ := os.ReadFile(target); string(content) != "3.14" {
+		t.Errorf("Existing file was modified by a failed write: %q", content)
+	}
+}
+
+// failingWriter accepts limit bytes, then fails every write
+type failingWriter struct {
+	limit   int
+	written int
+}
+
+var errDiskFull = errors.New("no space left on device")
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+	if w.written+len(b) > w.limit {
+		n := w.limit - w.written
+		w.written = w.limit
+		return n, errDiskFull
+	}
+	w.written += len(b)
+	return len(b), nil
+}
+
+func TestWriteDigitsToDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	err := WriteDigitsToFile([]int{3, 1, 4}, dir)
+	if !errors.Is(err, ErrOutputIsDirectory) {
+		t.Fatalf("Expected ErrOutputIsDirectory, got %v", err)
+	}
+	if err.Error() != "output path is a directory: "+dir {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+	if err := CheckOutputPath(dir); !errors.Is(err, ErrOutputIsDirectory) {
+		t.Errorf("Expected CheckOutputPath to report ErrOutputIsDirectory, got %v", err)
+	}
+
+	// No temporary file is left behind
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected an empty directory, found %d entries", len(entries))
+	}
+
+	missing := filepath.Join(dir, "missing", "pi.txt")
+	if err := WriteDigitsToFile([]int{3, 1, 4}, missing); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected a missing directory error, got %v", err)
+	}
+
+	// Root can write anywhere, so permissions can only be tested as a user
+	if os.Geteuid() != 0 {
+		readOnly := filepath.Join(dir, "readonly")
+		if err := os.Mkdir(readOnly, 0555); err != nil {
+			t.Fatal(err)
+		}
+		if err := CheckOutputPath(filepath.Join(readOnly, "pi.txt")); err == nil || !strings.Contains(err.Error(), "not writable") {
+			t.Errorf("Expected an unwritable directory error, got %v", err)
+		}
+	}
+
+	if err := CheckOutputPath(filepath.Join(dir, "pi.txt")); err != nil {
+		t.Errorf("Expected a writable path, got %v", err)
+	}
+}
+
+func TestWriteDigitsTextErrors(t *testing.T) {
+	digits := []int{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5}
+
+	// Fail on the leading "3.", within the first batch, and on a later batch
+	for _, limit := range []int{0, 1, 5, 8} {
+		w := &failingWriter{limit: limit}
+		err := WriteDigitsText(w, digits, TextOptions{BatchSize: 4})
+		if !errors.Is(err, errDiskFull) {
+			t.Errorf("Limit %d: expected the write error, got %v", limit, err)
+		}
+	}
+
+	if err := WriteDigitsText(&failingWriter{limit: 12}, digits, TextOptions{BatchSize: 4}); err != nil {
+		t.Errorf("Expected no error when every byte fits, got %v", err)
+	}
+
+	// The file writer wraps the error and leaves no file behind
+	path := filepath.Join(t.TempDir(), "pi.txt")
+	err := writeFileAtomic(path, func(f io.Writer) error {
+		return WriteDigitsText(io.MultiWriter(f, &failingWriter{limit: 6}), digits, TextOptions{})
+	})
+	if !errors.Is(err, errDiskFull) || !strings.Contains(err.Error(), "error writing file") {
+		t.Errorf("Expected a wrapped write error, got %v", err)
+	}
+	if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+		t.Errorf("Expected no file after a failed write, got %v", statErr)
+	}
+}
+
+// cancellingWriter cancels its context after the first write
+type cancellingWriter struct {
+	cancel context.CancelFunc
+	writes int
+}
+
+func (w *cancellingWriter) Write(b []byte) (int, error) {
+	w.writes++
+	w.cancel()
+	return len(b), nil
+}
+
+func TestWriteDigitsContext(t *testing.T) {
+	digits := make([]int, 10000)
+	digits[0] = 3
+
+	// Cancelling mid-write stops before the next batch
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &cancellingWriter{cancel: cancel}
+	if err := WriteDigitsContext(ctx, w, digits); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if w.writes != 1 {
+		t.Errorf("Expected to stop after the first write, got %d writes", w.writes)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDigitsContext(context.Background(), &buf, digits); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if buf.Len() != len(digits)+1 {
+		t.Errorf("Expected %d bytes, got %d", len(digits)+1, buf.Len())
+	}
+
+	// The file writer removes its temporary file and leaves filename alone
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pi.txt")
+	err := WriteDigitsToFileContext(ctx, digits, path, TextOptions{BatchSize: 100})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a wrapped context.Canceled, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected no files after a cancelled write, got %d", len(entries))
+	}
+}
+
+func TestWriteDigitsBatched(t *testing.T) {
+	digits := []int{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5}
+	path := filepath.Join(t.TempDir(), "pi.txt")
+
+	// Batch sizes that do and don't divide the digit count evenly
+	for _, batchSize := range []int{1, 3, 10, 100} {
+		if err := WriteDigitsToFileBatched(digits, path, batchSize); err != nil {
+			t.Fatalf("Batch size %d: failed to write: %v", batchSize, err)
+		}
+		if content, _ := os.ReadFile(path); string(content) != "3.1415926535" {
+			t.Errorf("Batch size %d: content mismatch, got %q", batchSize, content)
+		}
+	}
+
+	if err := WriteDigitsToFileBatched(digits, path, 0); err == nil {
+		t.Error("Expected an error for a zero batch size")
+	}
+
+	if got := defaultBatchSize(100); got != 1000 {
+		t.Errorf("Small outputs should use the minimum batch, got %d", got)
+	}
+	if got := defaultBatchSize(1 << 30); got != 1<<20 {
+		t.Errorf("Large outputs should use the maximum batch, got %d", got)
+	}
+}
+
+func TestWriteDigitsLineWidth(t *testing.T) {
+	pi := NewPi(25)
+	CalculatePi(25, pi)
+	digits := pi.GetDigits(26)
+	path := filepath.Join(t.TempDir(), "pi.txt")
+
+	// Line breaks must not depend on where the batches end
+	for _, batchSize := range []int{1, 7, 100} {
+		opts := TextOptions{LineWidth: 10, BatchSize: batchSize}
+		if err := WriteDigitsToFileWithOptions(digits, path, opts); err != nil {
+			t.Fatalf("Batch size %d: failed to write: %v", batchSize, err)
+		}
+
+		content, _ := os.ReadFile(path)
+		expected := "3.1415926535\n8979323846\n26433"
+		if string(content) != expected {
+			t.Errorf("Batch size %d: expected %q, got %q", batchSize, expected, content)
+		}
+		if sum := ChecksumText(content); sum != Checksum(digits) {
+			t.Errorf("Batch size %d: checksum should ignore line breaks, got %s", batchSize, sum)
+		}
+	}
+}
+
+func TestWriteDigitsDecimalSep(t *testing.T) {
+	digits := []int{3, 1, 4, 1, 5, 9}
+	path := filepath.Join(t.TempDir(), "pi.txt")
+
+	if err := WriteDigitsToFileWithOptions(digits, path, TextOptions{DecimalSep: ','}); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != "3,14159" {
+		t.Errorf("Expected 3,14159, got %q", content)
+	}
+
+	// Checksums, verification and validation normalize the separator to "."
+	if sum := ChecksumText(content); sum != Checksum(digits) {
+		t.Errorf("Checksum should ignore the decimal separator, got %s", sum)
+	}
+	if err := VerifyDigitsFile(digits, path); err != nil {
+		t.Errorf("Verification should accept a comma separator: %v", err)
+	}
+	pi := NewPi(5)
+	CalculatePi(5, pi)
+	if matched, err := ValidateAgainstFile(pi, 6, path); err != nil || matched != 6 {
+		t.Errorf("Expected 6 digits validated, got %d, %v", matched, err)
+	}
+}
+
+func TestWriteDigitsCRLF(t *testing.T) {
+	pi := NewPi(25)
+	CalculatePi(25, pi)
+	digits := pi.GetDigits(26)
+	path := filepath.Join(t.TempDir(), "pi.txt")
+
+	for _, batchSize := range []int{1, 7, 100} {
+		opts := TextOptions{LineWidth: 10, BatchSize: batchSize, CRLF: true}
+		if err := WriteDigitsToFileWithOptions(digits, path, opts); err != nil {
+			t.Fatalf("Batch size %d: failed to write: %v", batchSize, err)
+		}
+
+		content, _ := os.ReadFile(path)
+		expected := "3.1415926535\r\n8979323846\r\n26433"
+		if string(content) != expected {
+			t.Errorf("Batch size %d: expected %q, got %q", batchSize, expected, content)
+		}
+	}
+
+	// Checksums and verification ignore the line endings
+	content, _ := os.ReadFile(path)
+	if sum := ChecksumText(content); sum != Checksum(digits) {
+		t.Errorf("Checksum should ignore CRLF line breaks, got %s", sum)
+	}
+	if err := VerifyDigitsFile(digits, path); err != nil {
+		t.Errorf("Verification should accept CRLF line breaks: %v", err)
+	}
+}
+
 func TestConcurrency(t *testing.T) {
 	t.Run("ConcurrentReads", func(t *testing.T) {
 		// Test concurrent read safety
@@ -274,6 +1461,105 @@ func TestPerformance(t *testing.T) {
 	})
 }
 
+// BenchmarkRootCombine compares the allocations of combining the root with
+// and without P for 100k digits
+func BenchmarkRootCombine(b *testing.B) {
+	A := big.NewInt(13591409)
+	B := big.NewInt(545140134)
+	C3_24 := big.NewInt(640320 * 640320 * 640320 / 24)
+	terms := chudnovskyTerms(100_000)
+
+	b.Run("WithP", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			binarySplitParallel(context.Background(), 0, terms, A, B, C3_24, nil)
+		}
+	})
+
+	b.Run("WithoutP", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			binarySplitRoot(context.Background(), 0, terms, A, B, C3_24, nil, true)
+		}
+	})
+}
+
+// BenchmarkSqrtOverlap compares computing sqrt(10005) after the binary split
+// of 50k digits with computing it alongside, as calculatePiChudnovsky does
+// with GOMAXPROCS of 2 or more
+func BenchmarkSqrtOverlap(b *testing.B) {
+	A, B, C3_24 := chudnovskyConstants()
+	digits := int64(50_000)
+	terms := chudnovskyTerms(digits)
+	floatPrec := floatPrecision(digits)
+
+	b.Run("Serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			binarySplitRoot(context.Background(), 0, terms, A, B, C3_24, nil, true)
+			sqrt10005(floatPrec)
+		}
+	})
+
+	b.Run("Overlapped", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sqrtCh := make(chan *big.Float, 1)
+			go func() {
+				sqrtCh <- sqrt10005(floatPrec)
+			}()
+			binarySplitRoot(context.Background(), 0, terms, A, B, C3_24, nil, true)
+			<-sqrtCh
+		}
+	})
+}
+
+func BenchmarkWriteDigits(b *testing.B) {
+	digits := make([]int, 1_000_001)
+	digits[0] = 3
+	for i := 1; i < len(digits); i++ {
+		digits[i] = i % 10
+	}
+
+	path := filepath.Join(b.TempDir(), "pi.txt")
+	for _, batchSize := range []int{1000, 16 << 10, 1 << 20} {
+		b.Run(strconv.Itoa(batchSize), func(b *testing.B) {
+			b.SetBytes(int64(len(digits) + 1))
+			for i := 0; i < b.N; i++ {
+				if err := WriteDigitsToFileBatched(digits, path, batchSize); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkWriteDigitsParallel(b *testing.B) {
+	digits := make([]int, 10_000_001)
+	digits[0] = 3
+	for i := 1; i < len(digits); i++ {
+		digits[i] = i % 10
+	}
+
+	path := filepath.Join(b.TempDir(), "pi.txt")
+	b.Run("serial", func(b *testing.B) {
+		b.SetBytes(int64(len(digits) + 1))
+		for i := 0; i < b.N; i++ {
+			if err := WriteDigitsToFile(digits, path); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	for _, workers := range []int{2, 4, 8} {
+		b.Run("workers="+strconv.Itoa(workers), func(b *testing.B) {
+			b.SetBytes(int64(len(digits) + 1))
+			for i := 0; i < b.N; i++ {
+				if err := WriteDigitsToFileParallel(digits, path, workers); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 // Extended benchmark that verifies correctness for large calculations
 func BenchmarkLargeCalculation(b *testing.B) {
 	if testing.Short() {
@@ -306,3 +1592,44 @@ func BenchmarkLargeCalculation(b *testing.B) {
 		}
 	})
 }
+
+func TestPrewarmConstants(t *testing.T) {
+	orig := floatSqrt
+	defer func() {
+		floatSqrt = orig
+		sqrtCache.value = nil
+	}()
+
+	var calls atomic.Int64
+	floatSqrt = func(z, x *big.Float) *big.Float {
+		calls.Add(1)
+		return orig(z, x)
+	}
+
+	PrewarmConstants(2000)
+	if calls.Load() != 1 {
+		t.Fatalf("Expected prewarming to compute the square root once, got %d", calls.Load())
+	}
+
+	// Both the first run and the verification run reuse the cached root
+	pi := NewPi(2000)
+	CalculatePi(2000, pi)
+	if calls.Load() != 1 {
+		t.Errorf("Expected no square roots after prewarming, got %d", calls.Load()-1)
+	}
+	if _, err := pi.ValidateAgainstReference(ReferenceDigits); err != nil {
+		t.Errorf("Prewarmed calculation failed validation: %v", err)
+	}
+
+	// Prewarming a smaller precision keeps the larger root
+	PrewarmConstants(100)
+	if calls.Load() != 1 {
+		t.Errorf("Expected prewarming a smaller precision to be a no-op")
+	}
+
+	// Beyond the cached precision the root is computed again
+	CalculatePi(3000, NewPi(3000))
+	if calls.Load() == 1 {
+		t.Error("Expected a larger calculation to compute its own square root")
+	}
+}
@@ -0,0 +1,130 @@
+package picalc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sync/atomic"
+	"time"
+)
+
+// digitsPerRamanujanTerm is how many decimal digits each term of Ramanujan's
+// series adds, log10(396⁴ / 256)
+const digitsPerRamanujanTerm = 7.9825407783902
+
+// ramanujanTerms returns the number of Ramanujan series terms needed for
+// digits decimal digits, rounding up so a partial term is never dropped
+func ramanujanTerms(digits int64) int64 {
+	return int64(math.Ceil(float64(digits)/digitsPerRamanujanTerm)) + 1
+}
+
+// calculatePiRamanujan calculates pi to precision plus guard digits by summing
+// Ramanujan's 1910 series
+//
+//	1/π = 2√2/9801 Σ (4k)! (1103 + 26390k) / ((k!)⁴ 396^(4k))
+//
+// with binary splitting, counting completed terms on pi for progress reporting.
+// It matches calculatePiChudnovsky so calculatePiStable can run either.
+func calculatePiRamanujan(ctx context.Context, precision, guard int64, pi *Pi, timings *Timings) (decimal string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("pi calculation panicked: %v", r)
+		}
+	}()
+
+	digits := precision + guard
+	terms := ramanujanTerms(digits)
+	floatPrec := floatPrecision(digits)
+
+	phaseStart := time.Now()
+	_, Q, R, err := binarySplitRamanujan(ctx, 0, terms, &pi.computed)
+	if err != nil {
+		return "", err
+	}
+	timings.BinarySplit += lap(&phaseStart)
+
+	// Pi = 9801 / (2√2 R/Q) = (9801 √2 / 4) / (R/Q)
+	two := new(big.Float).SetPrec(floatPrec).SetInt64(2)
+	C := new(big.Float).SetPrec(floatPrec).Sqrt(two)
+	timings.Sqrt += lap(&phaseStart)
+
+	C.Mul(C, new(big.Float).SetPrec(floatPrec).SetInt64(9801))
+	C.Quo(C, new(big.Float).SetPrec(floatPrec).SetInt64(4))
+
+	result := make(chan finalResult, 1)
+	go func() {
+		result <- finalDivision(C, Q, R, floatPrec, digits)
+	}()
+
+	select {
+	case res := <-result:
+		if res.err != nil {
+			return "", res.err
+		}
+		timings.Division += res.division
+		timings.Extraction += res.extraction
+		return res.decimal, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// binarySplitRamanujan computes Ramanujan's series over [a, b) using binary
+// splitting. Term k is term k-1 times 8(2k-1)(4k-1)(4k-3) / (k³ 396⁴),
+// weighted by 1103 + 26390k. Ranges are summed serially; ctx is checked
+// before each range larger than the serial cutoff.
+func binarySplitRamanujan(ctx context.Context, a, b int64, done *atomic.Int64) (P, Q, R *big.Int, err error) {
+	if a == b {
+		return big.NewInt(1), big.NewInt(1), big.NewInt(0), nil
+	}
+
+	if b-a == 1 {
+		done.Add(1)
+		k := big.NewInt(a)
+		linear := func(m, c int64) *big.Int {
+			x := big.NewInt(m)
+			x.Mul(x, k)
+			return x.Add(x, big.NewInt(c))
+		}
+
+		weight := linear(26390, 1103)
+		if a == 0 {
+			return big.NewInt(1), big.NewInt(1), weight, nil
+		}
+
+		// P(a) = 8 (2a-1)(4a-1)(4a-3)
+		P = linear(2, -1)
+		P.Mul(P, linear(4, -1))
+		P.Mul(P, linear(4, -3))
+		P.Lsh(P, 3)
+
+		// Q(a) = a³ 396⁴
+		Q = new(big.Int).Mul(k, k)
+		Q.Mul(Q, k)
+		Q.Mul(Q, big.NewInt(396*396*396*396))
+
+		// R(a) = P(a) (1103 + 26390a)
+		R = new(big.Int).Mul(P, weight)
+		return P, Q, R, nil
+	}
+
+	if b-a > 100 {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	m := (a + b) / 2
+	P1, Q1, R1, err := binarySplitRamanujan(ctx, a, m, done)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	P2, Q2, R2, err := binarySplitRamanujan(ctx, m, b, done)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	P, Q, R = combinePQR(P1, Q1, R1, P2, Q2, R2)
+	return P, Q, R, nil
+}
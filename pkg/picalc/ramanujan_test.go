@@ -0,0 +1,107 @@
+package picalc
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRamanujan(t *testing.T) {
+	for _, precision := range []int64{100, 1000} {
+		pi := NewPiWithOptions(precision, Options{Algorithm: Ramanujan})
+		if err := CalculatePiContext(context.Background(), precision, pi); err != nil {
+			t.Fatalf("Precision %d: %v", precision, err)
+		}
+		if _, err := pi.ValidateAgainstReference(int(precision)); err != nil {
+			t.Errorf("Precision %d: %v", precision, err)
+		}
+
+		chudnovsky := NewPi(precision)
+		CalculatePi(precision, chudnovsky)
+		if !reflect.DeepEqual(pi.GetDigits(int(precision)), chudnovsky.GetDigits(int(precision))) {
+			t.Errorf("Precision %d: Ramanujan and Chudnovsky digits differ", precision)
+		}
+		if pi.GetProgress() != 100 {
+			t.Errorf("Precision %d: expected 100%% progress, got %.1f", precision, pi.GetProgress())
+		}
+	}
+}
+
+func TestRamanujanTermLargeIndex(t *testing.T) {
+	// 4a and 26390a overflow int64 for this a
+	const a = int64(1) << 61
+	var done atomic.Int64
+	P, Q, R, err := binarySplitRamanujan(context.Background(), a, a+1, &done)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bigA := big.NewInt(a)
+	linear := func(k, c int64) *big.Int {
+		x := new(big.Int).Mul(big.NewInt(k), bigA)
+		return x.Add(x, big.NewInt(c))
+	}
+
+	expectedP := new(big.Int).Mul(linear(2, -1), linear(4, -1))
+	expectedP.Mul(expectedP, linear(4, -3))
+	expectedP.Mul(expectedP, big.NewInt(8))
+	expectedQ := new(big.Int).Exp(bigA, big.NewInt(3), nil)
+	expectedQ.Mul(expectedQ, new(big.Int).Exp(big.NewInt(396), big.NewInt(4), nil))
+	expectedR := new(big.Int).Mul(expectedP, linear(26390, 1103))
+
+	if P.Cmp(expectedP) != 0 {
+		t.Errorf("P mismatch: expected %s, got %s", expectedP, P)
+	}
+	if Q.Cmp(expectedQ) != 0 {
+		t.Errorf("Q mismatch: expected %s, got %s", expectedQ, Q)
+	}
+	if R.Cmp(expectedR) != 0 {
+		t.Errorf("R mismatch: expected %s, got %s", expectedR, R)
+	}
+}
+
+func TestRamanujanCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pi := NewPiWithOptions(5000, Options{Algorithm: Ramanujan})
+	if err := CalculatePiContext(ctx, 5000, pi); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestAlgorithmString(t *testing.T) {
+	listed := make(map[string]bool)
+	for _, name := range Algorithms() {
+		listed[name] = true
+	}
+	for _, a := range []Algorithm{Chudnovsky, Ramanujan, Spigot} {
+		if !listed[a.String()] {
+			t.Errorf("Algorithm %q is missing from Algorithms()", a)
+		}
+	}
+}
+
+// BenchmarkSeries compares summing each series for the same number of
+// digits. Ramanujan needs about 1.8 times as many terms as Chudnovsky and its
+// sum takes correspondingly longer, which is why Chudnovsky is the default.
+// The final decimal conversion costs the same for both and dominates
+// CalculatePi at high precision, so this times the series alone.
+func BenchmarkSeries(b *testing.B) {
+	const digits = 100000
+
+	b.Run("chudnovsky", func(b *testing.B) {
+		A, B, C3_24 := chudnovskyConstants()
+		for i := 0; i < b.N; i++ {
+			binarySplitSerial(0, chudnovskyTerms(digits), A, B, C3_24, nil)
+		}
+	})
+	b.Run("ramanujan", func(b *testing.B) {
+		var done atomic.Int64
+		for i := 0; i < b.N; i++ {
+			binarySplitRamanujan(context.Background(), 0, ramanujanTerms(digits), &done)
+		}
+	})
+}
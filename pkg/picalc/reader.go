@@ -0,0 +1,156 @@
+package picalc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// DigitReader reads the digits of a Pi as ASCII text, "3." followed by the
+// fractional digits, exactly as WriteDigitsToFile would write them.
+// It implements io.Reader and io.Seeker. If the Pi is truncated below the
+// digits the reader was opened for, reads past its end fail with
+// io.ErrUnexpectedEOF.
+type DigitReader struct {
+	pi   *Pi
+	n    int   // digits available, including the leading 3
+	size int64 // total bytes of text
+	off  int64 // current byte offset
+}
+
+// NewDigitReader returns a reader over the first n digits of pi
+func NewDigitReader(pi *Pi, n int) *DigitReader {
+	n = min(max(n, 0), pi.numDigits())
+
+	// "3." plus one byte per fractional digit
+	size := int64(0)
+	if n > 0 {
+		size = int64(n) + 1
+	}
+
+	return &DigitReader{pi: pi, n: n, size: size}
+}
+
+// Read reads up to len(b) bytes of digit text
+func (r *DigitReader) Read(b []byte) (int, error) {
+	if r.off >= r.size {
+		return 0, io.EOF
+	}
+
+	r.pi.mutex.RLock()
+	defer r.pi.mutex.RUnlock()
+
+	read := 0
+	for read < len(b) && r.off < r.size {
+		// Byte offset k >= 2 holds fractional digit k-1
+		if i := max(r.off-1, 0); i >= int64(len(r.pi.digits)) {
+			return read, io.ErrUnexpectedEOF
+		}
+
+		switch r.off {
+		case 0:
+			b[read] = '0' + byte(r.pi.digits[0])
+		case 1:
+			b[read] = '.'
+		default:
+			b[read] = '0' + byte(r.pi.digits[r.off-1])
+		}
+		read++
+		r.off++
+	}
+
+	return read, nil
+}
+
+// Seek sets the byte offset for the next Read. Offset 2 is the first
+// fractional digit, so digit i (counting the leading 3 as 0) is at i+1.
+func (r *DigitReader) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = r.off + offset
+	case io.SeekEnd:
+		abs = r.size + offset
+	default:
+		return 0, errors.New("picalc.DigitReader.Seek: invalid whence")
+	}
+
+	if abs < 0 {
+		return 0, errors.New("picalc.DigitReader.Seek: negative position")
+	}
+
+	r.off = abs
+	return abs, nil
+}
+
+// Size returns the total number of bytes the reader produces
+func (r *DigitReader) Size() int64 {
+	return r.size
+}
+
+// gzipMagic starts every gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// gzipFile closes both the gzip stream and the file under it
+type gzipFile struct {
+	*gzip.Reader
+	f *os.File
+}
+
+func (g gzipFile) Close() error {
+	g.Reader.Close()
+	return g.f.Close()
+}
+
+// bufferedFile reads a file through the buffered reader used to sniff it
+type bufferedFile struct {
+	*bufio.Reader
+	f *os.File
+}
+
+func (b bufferedFile) Close() error {
+	return b.f.Close()
+}
+
+// openDigitReader opens a digits file for reading, transparently
+// decompressing it if it starts with the gzip magic bytes, whatever its name.
+// Every feature that reads digits files opens them through here.
+func openDigitReader(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(f)
+	magic, err := br.Peek(len(gzipMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		f.Close()
+		return nil, err
+	}
+	if !bytes.Equal(magic, gzipMagic) {
+		return bufferedFile{br, f}, nil
+	}
+
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error decompressing %s: %v", path, err)
+	}
+	return gzipFile{zr, f}, nil
+}
+
+// readDigitFile reads a whole digits file, decompressing it if needed
+func readDigitFile(path string) ([]byte, error) {
+	r, err := openDigitReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
@@ -0,0 +1,137 @@
+package picalc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDigitReader(t *testing.T) {
+	pi := NewPi(30)
+	CalculatePi(30, pi)
+
+	r := NewDigitReader(pi, 31)
+	all, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to read digits: %v", err)
+	}
+	if string(all) != "3.141592653589793238462643383279" {
+		t.Errorf("Unexpected digit text: %s", all)
+	}
+	if int64(len(all)) != r.Size() {
+		t.Errorf("Size %d doesn't match bytes read %d", r.Size(), len(all))
+	}
+}
+
+func TestDigitReaderSeek(t *testing.T) {
+	pi := NewPi(30)
+	CalculatePi(30, pi)
+	r := NewDigitReader(pi, 31)
+
+	// Jump to the middle: byte 16 is fractional digit 15
+	if pos, err := r.Seek(16, io.SeekStart); err != nil || pos != 16 {
+		t.Fatalf("Seek failed: pos %d, err %v", pos, err)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatalf("Read after seek failed: %v", err)
+	}
+	if string(buf) != "32384" {
+		t.Errorf("Expected 32384 after seeking, got %s", buf)
+	}
+
+	// Relative to the current position and the end
+	if _, err := r.Seek(-14, io.SeekCurrent); err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+	io.ReadFull(r, buf)
+	if string(buf) != "26535" {
+		t.Errorf("Expected 26535 after relative seek, got %s", buf)
+	}
+
+	r.Seek(-4, io.SeekEnd)
+	rest, _ := io.ReadAll(r)
+	if string(rest) != "3279" {
+		t.Errorf("Expected 3279 at the end, got %s", rest)
+	}
+
+	// Past the end reads EOF, negative positions are rejected
+	r.Seek(100, io.SeekStart)
+	if n, err := r.Read(buf); n != 0 || err != io.EOF {
+		t.Errorf("Expected EOF past the end, got %d, %v", n, err)
+	}
+	if _, err := r.Seek(-1, io.SeekStart); err == nil {
+		t.Error("Expected an error seeking before the start")
+	}
+
+	// The reader satisfies io.ReadSeeker
+	var _ io.ReadSeeker = r
+}
+
+// gzipFileCopy writes a gzip compressed copy of src to dst
+func gzipFileCopy(t *testing.T, src, dst string) {
+	t.Helper()
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+
+	if err := os.WriteFile(dst, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenDigitReaderGzip(t *testing.T) {
+	pi := NewPi(200)
+	CalculatePi(200, pi)
+	digits := pi.GetDigits(201)
+
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "pi.txt")
+	if err := WriteDigitsToFile(digits, plain); err != nil {
+		t.Fatal(err)
+	}
+	// Compressed files are detected by content, not by extension
+	compressed := filepath.Join(dir, "pi.dat")
+	gzipFileCopy(t, plain, compressed)
+
+	for _, path := range []string{plain, compressed} {
+		matched, err := ValidateAgainstFile(pi, 201, path)
+		if err != nil || matched != 201 {
+			t.Errorf("%s: expected a full match of 201 digits, got %d (%v)", path, matched, err)
+		}
+		if err := VerifyDigitsFile(digits, path); err != nil {
+			t.Errorf("%s: verification failed: %v", path, err)
+		}
+	}
+
+	binary := filepath.Join(dir, "pi.bin")
+	if err := WriteDigitsBinary(digits, binary); err != nil {
+		t.Fatal(err)
+	}
+	gzipFileCopy(t, binary, binary+".gz")
+	restored, err := ReadDigitsBinary(binary + ".gz")
+	if err != nil {
+		t.Fatalf("Error reading compressed binary digits: %v", err)
+	}
+	if !reflect.DeepEqual(restored, digits) {
+		t.Error("Compressed binary digits do not round-trip")
+	}
+
+	// A truncated gzip stream is an error rather than a short match
+	data, _ := os.ReadFile(compressed)
+	truncated := filepath.Join(dir, "truncated.gz")
+	os.WriteFile(truncated, data[:len(data)/2], 0644)
+	if _, err := ValidateAgainstFile(pi, 201, truncated); err == nil {
+		t.Error("Expected an error for a truncated gzip stream")
+	}
+}
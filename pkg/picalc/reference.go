@@ -0,0 +1,147 @@
+package picalc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// piReference holds the leading 3 and the first 1000 decimal digits of Pi,
+// used to validate computations without network access
+const piReference = "3" +
+	"14159265358979323846264338327950288419716939937510" +
+	"58209749445923078164062862089986280348253421170679" +
+	"82148086513282306647093844609550582231725359408128" +
+	"48111745028410270193852110555964462294895493038196" +
+	"44288109756659334461284756482337867831652712019091" +
+	"45648566923460348610454326648213393607260249141273" +
+	"72458700660631558817488152092096282925409171536436" +
+	"78925903600113305305488204665213841469519415116094" +
+	"33057270365759591953092186117381932611793105118548" +
+	"07446237996274956735188575272489122793818301194912" +
+	"98336733624406566430860213949463952247371907021798" +
+	"60943702770539217176293176752384674818467669405132" +
+	"00056812714526356082778577134275778960917363717872" +
+	"14684409012249534301465495853710507922796892589235" +
+	"42019956112129021960864034418159813629774771309960" +
+	"51870721134999999837297804995105973173281609631859" +
+	"50244594553469083026425223082533446850352619311881" +
+	"71010003137838752886587533208381420617177669147303" +
+	"59825349042875546873115956286388235378759375195778" +
+	"18577805321712268066130019278766111959092164201989"
+
+// ReferenceDigits is the number of digits (including the leading 3) in the embedded reference
+const ReferenceDigits = len(piReference)
+
+// DigitsEqual reports whether a and b hold the same digits, and the index of
+// the first digit at which they differ. Where one is a prefix of the other
+// that is the shorter length, and for equal slices it is their length, so
+// the index is always how many leading digits agree.
+func DigitsEqual(a, b []int) (bool, int) {
+	n := min(len(a), len(b))
+	for i := range n {
+		if a[i] != b[i] {
+			return false, i
+		}
+	}
+	return len(a) == len(b), n
+}
+
+// ValidateAgainstReference compares the first n computed digits against the
+// embedded reference and returns how many of them match. n is capped at
+// ReferenceDigits. A mismatch is reported as an error naming its position.
+func (p *Pi) ValidateAgainstReference(n int) (int, error) {
+	if n > ReferenceDigits {
+		n = ReferenceDigits
+	}
+
+	digits := p.GetDigits(n)
+	for i, d := range digits {
+		expected := int(piReference[i] - '0')
+		if d != expected {
+			return i, fmt.Errorf("digit %d mismatch: expected %d, got %d", i, expected, d)
+		}
+	}
+
+	return len(digits), nil
+}
+
+// stdlibPiDigits holds the digits of math.Pi as a float64 in its shortest
+// round-tripping form, "3141592653589793", all of which are true digits
+var stdlibPiDigits = strings.Replace(strconv.FormatFloat(math.Pi, 'f', -1, 64), ".", "", 1)
+
+// CompareToStdlib returns how many of the first n computed digits, counting
+// the 3, match math.Pi before the first difference, as a quick sanity check.
+// Only the 16 digits math.Pi holds as a float64 are compared, so a correct
+// calculation returns min(n, 16).
+func (p *Pi) CompareToStdlib(n int) int {
+	n = min(n, len(stdlibPiDigits))
+
+	matched := 0
+	for _, d := range p.GetDigits(n) {
+		if d != int(stdlibPiDigits[matched]-'0') {
+			break
+		}
+		matched++
+	}
+	return matched
+}
+
+// ValidateAgainstFile compares the first n computed digits against a trusted
+// digits file at refPath, streaming it rather than reading it whole. The
+// file may hold "3.1415...", "31415..." or just the fractional digits, with
+// any decimal separator and line breaks, or be a WriteDigitsBinary file, and
+// may be gzip compressed. Like
+// ValidateAgainstReference it returns the number of digits that match,
+// stopping early if the reference holds fewer than n, or the position of the
+// first divergent digit along with an error.
+func ValidateAgainstFile(pi *Pi, n int, refPath string) (int, error) {
+	f, err := openDigitReader(refPath)
+	if err != nil {
+		return 0, fmt.Errorf("error opening reference: %v", err)
+	}
+	defer f.Close()
+
+	digits := pi.GetDigits(n)
+	r := bufio.NewReader(f)
+	if header, _ := r.Peek(len(digitsFileMagic)); isDigitsBinary(header) {
+		return matchDigitsBinary(r, digits)
+	}
+
+	// Fractional-only references start at 1, never at the leading 3
+	i := 0
+	for i < len(digits) {
+		c, err := r.ReadByte()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return i, fmt.Errorf("error reading reference: %v", err)
+		}
+
+		switch {
+		case c == '\n' || c == '\r' || c == ' ' || c == '\t':
+			continue
+		case i == 1 && isDecimalSep(c):
+			continue
+		case c < '0' || c > '9':
+			return i, fmt.Errorf("reference contains unexpected character %q", c)
+		}
+
+		if i == 0 && c != '3' {
+			if i = 1; i == len(digits) {
+				break
+			}
+		}
+		if expected := int(c - '0'); digits[i] != expected {
+			return i, fmt.Errorf("digit %d mismatch: expected %d, got %d", i, expected, digits[i])
+		}
+		i++
+	}
+
+	return i, nil
+}
@@ -0,0 +1,164 @@
+package picalc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateAgainstReference(t *testing.T) {
+	pi := NewPi(1000)
+	CalculatePi(1000, pi)
+
+	matched, err := pi.ValidateAgainstReference(1001)
+	if err != nil {
+		t.Fatalf("Correct computation failed validation: %v", err)
+	}
+	if matched != 1001 {
+		t.Errorf("Expected 1001 matching digits, got %d", matched)
+	}
+
+	// Requests beyond the reference are capped
+	matched, _ = pi.ValidateAgainstReference(5000)
+	if matched != ReferenceDigits {
+		t.Errorf("Expected validation capped at %d digits, got %d", ReferenceDigits, matched)
+	}
+
+	// Corrupt a digit and expect the mismatch position
+	pi.digits[42] = (pi.digits[42] + 1) % 10
+	matched, err = pi.ValidateAgainstReference(1001)
+	if err == nil {
+		t.Fatal("Expected an error for a corrupted digit")
+	}
+	if matched != 42 {
+		t.Errorf("Expected mismatch at position 42, got %d", matched)
+	}
+}
+
+func TestCompareToStdlib(t *testing.T) {
+	if stdlibPiDigits != "3141592653589793" {
+		t.Fatalf("Unexpected math.Pi digits %s", stdlibPiDigits)
+	}
+
+	pi := NewPi(50)
+	CalculatePi(50, pi)
+
+	for n, expected := range map[int]int{0: 0, 5: 5, 16: 16, 51: 16, 1000: 16} {
+		if got := pi.CompareToStdlib(n); got != expected {
+			t.Errorf("CompareToStdlib(%d): expected %d, got %d", n, expected, got)
+		}
+	}
+
+	// A wrong digit stops the count
+	pi.digits[7] = (pi.digits[7] + 1) % 10
+	if got := pi.CompareToStdlib(51); got != 7 {
+		t.Errorf("Expected 7 matching digits before the corrupted one, got %d", got)
+	}
+}
+
+func TestDigitsEqual(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		a, b  []int
+		equal bool
+		index int
+	}{
+		{"Equal", []int{3, 1, 4}, []int{3, 1, 4}, true, 3},
+		{"BothEmpty", nil, []int{}, true, 0},
+		{"FirstDifference", []int{3, 1, 4, 1}, []int{3, 1, 5, 1}, false, 2},
+		{"DifferAtStart", []int{3}, []int{4}, false, 0},
+		{"ShorterA", []int{3, 1}, []int{3, 1, 4}, false, 2},
+		{"ShorterB", []int{3, 1, 4}, []int{3}, false, 1},
+	} {
+		equal, index := DigitsEqual(tt.a, tt.b)
+		if equal != tt.equal || index != tt.index {
+			t.Errorf("%s: expected (%v, %d), got (%v, %d)", tt.name, tt.equal, tt.index, equal, index)
+		}
+	}
+}
+
+func TestValidateAgainstFile(t *testing.T) {
+	pi := NewPi(100)
+	CalculatePi(100, pi)
+
+	dir := t.TempDir()
+	write := func(name, text string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	full := piReference[:1] + "." + piReference[1:201]
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{"full", full, 101},
+		{"no dot", piReference[:201], 101},
+		{"fractional", piReference[1:201] + "\n", 101},
+		{"wrapped", full[:30] + "\n" + full[30:], 101},
+		{"short", full[:22], 21},
+	}
+	for _, tt := range tests {
+		matched, err := ValidateAgainstFile(pi, 101, write(tt.name, tt.text))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if matched != tt.expected {
+			t.Errorf("%s: expected %d matching digits, got %d", tt.name, tt.expected, matched)
+		}
+	}
+
+	// 3.14159 -> 3.14158
+	matched, err := ValidateAgainstFile(pi, 101, write("bad", "3.14158"))
+	if err == nil || matched != 5 {
+		t.Errorf("Expected divergence at digit 5, got %d (%v)", matched, err)
+	}
+
+	if _, err := ValidateAgainstFile(pi, 101, write("junk", "3.14x")); err == nil {
+		t.Error("Expected an error for a non-digit character")
+	}
+	if _, err := ValidateAgainstFile(pi, 101, filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing reference")
+	}
+
+	// Binary references are streamed too, whether longer or shorter than n
+	referenceDigits := func(n int) []int {
+		digits := make([]int, n)
+		for i := range digits {
+			digits[i] = int(piReference[i] - '0')
+		}
+		return digits
+	}
+	for _, count := range []int{201, 22, 21} {
+		path := filepath.Join(dir, fmt.Sprintf("binary-%d", count))
+		if err := WriteDigitsBinary(referenceDigits(count), path); err != nil {
+			t.Fatal(err)
+		}
+		matched, err := ValidateAgainstFile(pi, 101, path)
+		if expected := min(count, 101); err != nil || matched != expected {
+			t.Errorf("Binary reference of %d digits: expected %d matching digits, got %d (%v)", count, expected, matched, err)
+		}
+	}
+
+	bad := referenceDigits(101)
+	bad[5] = 8
+	path := filepath.Join(dir, "binary-bad")
+	if err := WriteDigitsBinary(bad, path); err != nil {
+		t.Fatal(err)
+	}
+	if matched, err := ValidateAgainstFile(pi, 101, path); err == nil || matched != 5 {
+		t.Errorf("Expected divergence at digit 5 in a binary reference, got %d (%v)", matched, err)
+	}
+
+	// A header promising more digits than the file holds
+	data, _ := os.ReadFile(path)
+	if _, err := ValidateAgainstFile(pi, 101, write("binary-truncated", string(data[:digitsHeaderSize+2]))); !errors.Is(err, ErrInvalidDigitsFile) {
+		t.Errorf("Expected ErrInvalidDigitsFile for a truncated binary reference, got %v", err)
+	}
+}
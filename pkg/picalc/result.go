@@ -0,0 +1,47 @@
+package picalc
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Result is a completed calculation of Pi, for callers that would rather
+// not manage a Pi themselves
+type Result struct {
+	Precision      int64         `json:"precision"`
+	Digits         []int         `json:"digits"` // the leading 3 and the fractional digits
+	Duration       time.Duration `json:"duration"`
+	Algorithm      string        `json:"algorithm"`
+	Checksum       string        `json:"checksum"`
+	ReliableDigits int           `json:"reliable_digits"`
+}
+
+// MaxPrecision is the largest precision CalculatePiResult accepts. A
+// big.Float holds at most about 1.29 billion decimal digits, which leaves
+// room for the guard digits on top of this.
+const MaxPrecision = 1_000_000_000
+
+// CalculatePiResult calculates Pi to precision decimal places with the
+// default algorithm and returns the digits along with how they were computed.
+// precision must be between 0 and MaxPrecision.
+func CalculatePiResult(precision int64) (*Result, error) {
+	if precision < 0 || precision > MaxPrecision {
+		return nil, fmt.Errorf("precision must be between 0 and %d, got %d", MaxPrecision, precision)
+	}
+
+	pi := NewPi(precision)
+	if err := CalculatePiContext(context.Background(), precision, pi); err != nil {
+		return nil, err
+	}
+
+	digits := pi.GetDigits(int(precision) + 1)
+	return &Result{
+		Precision:      precision,
+		Digits:         digits,
+		Duration:       pi.Duration(),
+		Algorithm:      pi.algorithm.String(),
+		Checksum:       Checksum(digits),
+		ReliableDigits: pi.ReliableDigits(),
+	}, nil
+}
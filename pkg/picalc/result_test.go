@@ -0,0 +1,40 @@
+package picalc
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCalculatePiResult(t *testing.T) {
+	res, err := CalculatePiResult(100)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	pi := NewPi(100)
+	CalculatePi(100, pi)
+
+	if res.Precision != 100 || res.Algorithm != pi.algorithm.String() {
+		t.Errorf("Unexpected precision or algorithm: %d, %s", res.Precision, res.Algorithm)
+	}
+	if !reflect.DeepEqual(res.Digits, pi.GetDigits(101)) {
+		t.Error("Result digits differ from an in-place calculation")
+	}
+	if res.Checksum != pi.Checksum() {
+		t.Errorf("Expected checksum %s, got %s", pi.Checksum(), res.Checksum)
+	}
+	if res.ReliableDigits != pi.ReliableDigits() || res.ReliableDigits != len(res.Digits) {
+		t.Errorf("Expected %d reliable digits, got %d", len(res.Digits), res.ReliableDigits)
+	}
+	if res.Duration <= 0 {
+		t.Errorf("Expected a positive duration, got %v", res.Duration)
+	}
+
+	if _, err := CalculatePiResult(-1); err == nil {
+		t.Error("Expected an error for negative precision")
+	}
+	if _, err := CalculatePiResult(math.MaxInt64); err == nil {
+		t.Error("Expected an error for precision beyond MaxPrecision")
+	}
+}
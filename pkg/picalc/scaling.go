@@ -0,0 +1,97 @@
+package picalc
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"time"
+)
+
+// ProfilePoint is the cost of calculating Pi to one precision
+type ProfilePoint struct {
+	Precision int64
+	Duration  time.Duration
+	Bytes     uint64 // total bytes allocated during the calculation
+}
+
+// ScalingProfile calculates Pi at each precision in turn and returns how long
+// each took and how much it allocated, one point per precision
+func ScalingProfile(precisions []int64) []ProfilePoint {
+	points := make([]ProfilePoint, 0, len(precisions))
+
+	for _, precision := range precisions {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		start := time.Now()
+		pi := NewPi(precision)
+		CalculatePi(precision, pi)
+		elapsed := time.Since(start)
+
+		runtime.ReadMemStats(&after)
+		points = append(points, ProfilePoint{
+			Precision: precision,
+			Duration:  elapsed,
+			Bytes:     after.TotalAlloc - before.TotalAlloc,
+		})
+	}
+
+	return points
+}
+
+// ScalingExponent fits duration = c * precision^k to points by least squares
+// on a log-log scale and returns k, or NaN with fewer than two usable points
+func ScalingExponent(points []ProfilePoint) float64 {
+	var n, sumX, sumY, sumXY, sumXX float64
+	for _, p := range points {
+		if p.Precision <= 0 || p.Duration <= 0 {
+			continue
+		}
+		x := math.Log(float64(p.Precision))
+		y := math.Log(float64(p.Duration))
+		n++
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	denom := n*sumXX - sumX*sumX
+	if n < 2 || denom == 0 {
+		return math.NaN()
+	}
+	return (n*sumXY - sumX*sumY) / denom
+}
+
+// throughputStart is the first precision MeasureThroughput tries
+const throughputStart = 1000
+
+// MeasureThroughput calculates Pi at doubling precisions until budget runs
+// out and returns the digits per second of the largest calculation that
+// completed. Small calculations run faster per digit, so the largest one is
+// the rate the machine sustains at scale. A calculation still running at the
+// budget is cancelled and not counted; the first, small one always completes
+// so the result is positive.
+func MeasureThroughput(budget time.Duration) (digitsPerSec float64) {
+	ctx, cancel := context.WithTimeout(context.Background(), budget)
+	defer cancel()
+
+	for precision := int64(throughputStart); ; precision *= 2 {
+		runCtx := ctx
+		if precision == throughputStart {
+			runCtx = context.Background()
+		}
+
+		pi := NewPi(precision)
+		start := time.Now()
+		if err := CalculatePiContext(runCtx, precision, pi); err != nil {
+			return digitsPerSec
+		}
+		digitsPerSec = float64(precision) / time.Since(start).Seconds()
+
+		if ctx.Err() != nil {
+			return digitsPerSec
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package picalc
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestScalingProfile(t *testing.T) {
+	precisions := []int64{10, 1000, 20000}
+	points := ScalingProfile(precisions)
+
+	if len(points) != len(precisions) {
+		t.Fatalf("Expected %d points, got %d", len(precisions), len(points))
+	}
+	for i, p := range points {
+		if p.Precision != precisions[i] || p.Duration <= 0 {
+			t.Errorf("Unexpected point %d: %+v", i, p)
+		}
+	}
+
+	// Timings are noisy, but 20000 digits must cost more than 10
+	if points[2].Duration <= points[0].Duration {
+		t.Errorf("Expected time to grow with precision: %v", points)
+	}
+	if points[2].Bytes <= points[0].Bytes {
+		t.Errorf("Expected allocations to grow with precision: %v", points)
+	}
+}
+
+func TestScalingExponent(t *testing.T) {
+	// duration = precision^1.5 microseconds
+	var points []ProfilePoint
+	for _, p := range []int64{100, 1000, 10000} {
+		points = append(points, ProfilePoint{
+			Precision: p,
+			Duration:  time.Duration(math.Pow(float64(p), 1.5)) * time.Microsecond,
+		})
+	}
+
+	if k := ScalingExponent(points); math.Abs(k-1.5) > 1e-6 {
+		t.Errorf("Expected exponent 1.5, got %f", k)
+	}
+	if k := ScalingExponent(points[:1]); !math.IsNaN(k) {
+		t.Errorf("Expected NaN for a single point, got %f", k)
+	}
+}
+
+func TestMeasureThroughput(t *testing.T) {
+	start := time.Now()
+	rate := MeasureThroughput(50 * time.Millisecond)
+	elapsed := time.Since(start)
+
+	if rate <= 0 {
+		t.Errorf("Expected a positive throughput, got %f", rate)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("Expected to stop near the 50ms budget, took %v", elapsed)
+	}
+
+	// Even without any budget the first calculation is measured
+	if rate := MeasureThroughput(0); rate <= 0 {
+		t.Errorf("Expected a positive throughput with no budget, got %f", rate)
+	}
+}
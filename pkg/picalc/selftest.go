@@ -0,0 +1,131 @@
+package picalc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// selfTestDigits is the precision the self-test calculations run at
+const selfTestDigits = 500
+
+// selfTestHexDigits is how many hex digits the BBP check compares
+const selfTestHexDigits = 100
+
+// CheckResult is the outcome of one RunSelfTest check
+type CheckResult struct {
+	Name   string
+	Passed bool
+	Detail string
+}
+
+// RunSelfTest runs a battery of internal consistency checks, each comparing
+// independent ways of producing the same digits, so a build on new hardware
+// can be trusted. Every check runs even if an earlier one fails.
+func RunSelfTest() []CheckResult {
+	checks := []struct {
+		name string
+		run  func() (string, error)
+	}{
+		{"chudnovsky matches reference", checkReference},
+		{"chudnovsky matches spigot", checkSpigot},
+		{"bbp hex matches decimal", checkHex},
+		{"checksum is reproducible", checkChecksum},
+	}
+
+	results := make([]CheckResult, 0, len(checks))
+	for _, c := range checks {
+		detail, err := c.run()
+		if err != nil {
+			detail = err.Error()
+		}
+		results = append(results, CheckResult{Name: c.name, Passed: err == nil, Detail: detail})
+	}
+	return results
+}
+
+// selfTestPi calculates Pi to selfTestDigits places with the Chudnovsky algorithm
+func selfTestPi() (*Pi, error) {
+	pi := NewPi(selfTestDigits)
+	if err := CalculatePiContext(context.Background(), selfTestDigits, pi); err != nil {
+		return nil, err
+	}
+	return pi, nil
+}
+
+func checkReference() (string, error) {
+	pi, err := selfTestPi()
+	if err != nil {
+		return "", err
+	}
+	matched, err := pi.ValidateAgainstReference(selfTestDigits + 1)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d digits match the embedded reference", matched), nil
+}
+
+func checkSpigot() (string, error) {
+	pi, err := selfTestPi()
+	if err != nil {
+		return "", err
+	}
+
+	spigot := NewPi(selfTestDigits)
+	if err := StreamPiSpigot(context.Background(), selfTestDigits, spigot, io.Discard); err != nil {
+		return "", err
+	}
+
+	a, b := pi.GetDigits(selfTestDigits+1), spigot.GetDigits(selfTestDigits+1)
+	if equal, i := DigitsEqual(a, b); !equal {
+		return "", fmt.Errorf("digit %d differs: chudnovsky %d, spigot %d", i, a[i], b[i])
+	}
+	return fmt.Sprintf("%d digits agree", len(a)), nil
+}
+
+func checkHex() (string, error) {
+	pi, err := selfTestPi()
+	if err != nil {
+		return "", err
+	}
+
+	// Convert the decimal fraction to hex: multiply by 16 and take the integer part
+	frac, _ := new(big.Int).SetString(FormatPlain(pi.GetDigits(selfTestDigits + 1)[1:]), 10)
+	den := new(big.Int).Exp(big.NewInt(10), big.NewInt(selfTestDigits), nil)
+	sixteen := big.NewInt(16)
+	converted := make([]byte, selfTestHexDigits)
+	d := new(big.Int)
+	for i := range converted {
+		frac.Mul(frac, sixteen)
+		d.QuoRem(frac, den, frac)
+		converted[i] = hexChars[d.Int64()]
+	}
+
+	bbp, err := HexDigits(0, selfTestHexDigits, 0)
+	if err != nil {
+		return "", err
+	}
+	for i := range converted {
+		if converted[i] != bbp[i] {
+			return "", fmt.Errorf("hex digit %d differs: decimal gives %c, bbp gives %c", i, converted[i], bbp[i])
+		}
+	}
+	return fmt.Sprintf("%d hex digits agree", selfTestHexDigits), nil
+}
+
+func checkChecksum() (string, error) {
+	first, err := selfTestPi()
+	if err != nil {
+		return "", err
+	}
+	second, err := selfTestPi()
+	if err != nil {
+		return "", err
+	}
+
+	if a, b := first.Checksum(), second.Checksum(); a != b {
+		return "", fmt.Errorf("checksums differ between runs: %s and %s", a, b)
+	}
+	return first.Checksum(), nil
+}
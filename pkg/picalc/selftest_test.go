@@ -0,0 +1,15 @@
+package picalc
+
+import "testing"
+
+func TestRunSelfTest(t *testing.T) {
+	results := RunSelfTest()
+	if len(results) == 0 {
+		t.Fatal("Expected self-test checks")
+	}
+	for _, r := range results {
+		if !r.Passed {
+			t.Errorf("Check %q failed: %s", r.Name, r.Detail)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package picalc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// spigotGuardDigits is how many digits beyond those emitted the spigot's
+// state can represent, so a short run of held-back nines can still resolve
+const spigotGuardDigits = 10
+
+// StreamPiSpigot calculates decimal digits of Pi with the Rabinowitz–Wagon
+// spigot algorithm, writing "3." and each fractional digit to w as soon as it
+// is known to be final. The digits are also stored in pi and each emitted
+// digit counts towards GetProgress, so a progress bar works as for
+// CalculatePiContext. Digits are truncated regardless of Options.Rounding.
+//
+// The spigot needs O(precision²) time and is meant for streaming modest
+// precisions; use CalculatePiContext for large ones. With
+// Options.RetainDigits only the last digits written are kept in pi.
+func StreamPiSpigot(ctx context.Context, precision int64, pi *Pi, w io.Writer) error {
+	startTime := time.Now()
+	defer func() { pi.elapsed = time.Since(startTime) }()
+
+	if precision < 0 {
+		return fmt.Errorf("precision must be non-negative, got %d", precision)
+	}
+
+	// Progress is measured in digits rather than series terms here
+	needed := precision + 1 // the 3 and the fractional digits
+	pi.complete.Store(false)
+	pi.computed.Store(0)
+	pi.totalTerms.Store(needed)
+	if pi.ring != nil {
+		pi.ring.reset()
+	}
+
+	var emitted int64
+	emit := func(d int64) error {
+		if emitted >= needed {
+			return nil
+		}
+
+		text := []byte{'0' + byte(d)}
+		if emitted == 0 {
+			text = append(text, '.')
+		}
+		if _, err := w.Write(text); err != nil {
+			return fmt.Errorf("error writing digit %d: %w", emitted, err)
+		}
+
+		pi.mutex.Lock()
+		if pi.ring != nil {
+			pi.ring.push(int(d))
+		} else if emitted < int64(len(pi.digits)) {
+			pi.digits[emitted] = int(d)
+		}
+		pi.mutex.Unlock()
+
+		emitted++
+		pi.computed.Add(1)
+		return nil
+	}
+
+	// Pi as a mixed-radix number with bases 1/3, 2/5, 3/7, ... and every digit 2
+	n := needed + spigotGuardDigits
+	a := make([]int64, 10*n/3+1)
+	for i := range a {
+		a[i] = 2
+	}
+
+	// A digit is held back while the digits after it could still carry into it
+	var predigit, nines int64
+	for j := int64(0); j < n && emitted < needed; j++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Multiply by 10 and normalize, carrying from the right
+		var q int64
+		for i := int64(len(a)); i > 0; i-- {
+			x := 10*a[i-1] + q*i
+			a[i-1] = x % (2*i - 1)
+			q = x / (2*i - 1)
+		}
+		a[0] = q % 10
+		q /= 10
+
+		switch {
+		case q == 9:
+			nines++
+		case q == 10:
+			// Carry into the held digits: predigit+1 followed by zeros
+			if err := emit(predigit + 1); err != nil {
+				return err
+			}
+			for ; nines > 0; nines-- {
+				if err := emit(0); err != nil {
+					return err
+				}
+			}
+			predigit = 0
+		default:
+			if j > 0 {
+				if err := emit(predigit); err != nil {
+					return err
+				}
+			}
+			predigit = q
+			for ; nines > 0; nines-- {
+				if err := emit(9); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Flush digits still held back when the guard digits ran out
+	if err := emit(predigit); err != nil {
+		return err
+	}
+	for ; nines > 0; nines-- {
+		if err := emit(9); err != nil {
+			return err
+		}
+	}
+
+	pi.complete.Store(true)
+	return nil
+}
+
+// spigotDecimal runs the spigot for CalculatePiContext and returns the digits
+// as a decimal string. Spigot digits are exact, so guard digits are only
+// computed when a rounding mode needs them.
+func spigotDecimal(ctx context.Context, precision int64, pi *Pi) (string, error) {
+	digits := precision
+	if pi.opts.Rounding != RoundDown {
+		digits += initialGuardDigits
+	}
+
+	// Progress is all or nothing, as for the hardcoded value
+	pi.computed.Store(0)
+	pi.totalTerms.Store(1)
+
+	var buf strings.Builder
+	buf.Grow(int(digits) + 2)
+	if err := StreamPiSpigot(ctx, digits, NewPiWithOptions(digits, Options{RetainDigits: 1}), &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// digitRing holds the last len(buf) of a stream of digits
+type digitRing struct {
+	buf   []int
+	total int64 // digits pushed so far
+}
+
+// newDigitRing returns a ring holding the last size digits, at least one
+func newDigitRing(size int) *digitRing {
+	return &digitRing{buf: make([]int, max(size, 1))}
+}
+
+// push appends d, overwriting the oldest digit once the ring is full
+func (r *digitRing) push(d int) {
+	r.buf[r.total%int64(len(r.buf))] = d
+	r.total++
+}
+
+// reset empties the ring
+func (r *digitRing) reset() {
+	r.total = 0
+}
+
+// ordered returns the position of the oldest digit held and the digits
+// held, oldest first
+func (r *digitRing) ordered() (int64, []int) {
+	size := int64(len(r.buf))
+	if r.total <= size {
+		return 0, append([]int(nil), r.buf[:r.total]...)
+	}
+
+	next := r.total % size
+	out := make([]int, 0, size)
+	out = append(out, r.buf[next:]...)
+	out = append(out, r.buf[:next]...)
+	return r.total - size, out
+}
+
+// RetainedDigits returns the digits kept in memory and the position of the
+// first of them, where position 0 is the leading 3. With
+// Options.RetainDigits these are the last digits streamed; otherwise they
+// are all the digits, starting at 0.
+func (p *Pi) RetainedDigits() (start int64, digits []int) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if p.ring != nil {
+		return p.ring.ordered()
+	}
+	return 0, append([]int(nil), p.digits...)
+}
@@ -0,0 +1,108 @@
+package picalc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// progressWriter records the progress reported at each write
+type progressWriter struct {
+	bytes.Buffer
+	pi       *Pi
+	progress []float64
+}
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	w.progress = append(w.progress, w.pi.GetProgress())
+	return w.Buffer.Write(b)
+}
+
+func TestStreamPiSpigot(t *testing.T) {
+	// Includes the run of six nines at position 762
+	const precision = 800
+
+	reference := NewPi(precision)
+	CalculatePi(precision, reference)
+
+	pi := NewPi(precision)
+	w := &progressWriter{pi: pi}
+	if err := StreamPiSpigot(context.Background(), precision, pi, w); err != nil {
+		t.Fatalf("StreamPiSpigot failed: %v", err)
+	}
+
+	if !strings.HasPrefix(w.String(), "3.14159265358979") || len(w.String()) != precision+2 {
+		t.Errorf("Unexpected streamed text: %.20s... (%d bytes)", w.String(), len(w.String()))
+	}
+	if !reflect.DeepEqual(pi.GetDigits(precision+1), reference.GetDigits(precision+1)) {
+		t.Error("Streamed digits differ from the Chudnovsky digits")
+	}
+
+	if len(w.progress) != precision+1 {
+		t.Fatalf("Expected %d writes, got %d", precision+1, len(w.progress))
+	}
+	for i := 1; i < len(w.progress); i++ {
+		if w.progress[i] <= w.progress[i-1] {
+			t.Fatalf("Progress did not advance at digit %d: %f then %f", i, w.progress[i-1], w.progress[i])
+		}
+	}
+	if progress := pi.GetProgress(); progress != 100.0 {
+		t.Errorf("Progress should be 100%% after streaming, got %f", progress)
+	}
+}
+
+func TestStreamPiSpigotCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var buf bytes.Buffer
+	if err := StreamPiSpigot(ctx, 100, NewPi(100), &buf); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestStreamPiSpigotRetainDigits(t *testing.T) {
+	const precision, keep = 800, 50
+
+	reference := NewPi(precision)
+	CalculatePi(precision, reference)
+	want := reference.GetDigits(precision + 1)
+
+	pi := NewPiWithOptions(precision, Options{RetainDigits: keep})
+	var buf bytes.Buffer
+	if err := StreamPiSpigot(context.Background(), precision, pi, &buf); err != nil {
+		t.Fatalf("StreamPiSpigot failed: %v", err)
+	}
+
+	// The output is complete even though only the last digits are kept
+	if buf.String() != FormatGrouped(want, 0, "") {
+		t.Error("Streamed text differs from the Chudnovsky digits")
+	}
+	if len(pi.digits) != 0 || len(pi.ring.buf) != keep {
+		t.Errorf("Expected only %d digits in memory, got %d stored and a ring of %d", keep, len(pi.digits), len(pi.ring.buf))
+	}
+
+	start, digits := pi.RetainedDigits()
+	if start != precision+1-keep {
+		t.Errorf("Expected the retained digits to start at %d, got %d", precision+1-keep, start)
+	}
+	if !reflect.DeepEqual(digits, want[start:]) {
+		t.Errorf("Retained digits %v differ from %v", digits, want[start:])
+	}
+
+	// A ring larger than the stream holds everything from position 0
+	small := NewPiWithOptions(10, Options{RetainDigits: keep})
+	if err := StreamPiSpigot(context.Background(), 10, small, &bytes.Buffer{}); err != nil {
+		t.Fatalf("StreamPiSpigot failed: %v", err)
+	}
+	if start, digits := small.RetainedDigits(); start != 0 || !reflect.DeepEqual(digits, want[:11]) {
+		t.Errorf("Expected all 11 digits from 0, got %v from %d", digits, start)
+	}
+
+	if err := CalculatePiContext(context.Background(), precision, pi); err == nil {
+		t.Error("Expected CalculatePiContext to reject a Pi that retains only some digits")
+	}
+}
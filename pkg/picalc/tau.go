@@ -0,0 +1,38 @@
+package picalc
+
+import "context"
+
+// CalculateTau calculates decimal digits of Tau (2π) into pi, so that
+// pi.GetDigits returns 6, 2, 8, 3, 1, 8, 5, ... Pi is computed with guard
+// digits and doubled as a decimal string, carrying into the integer part.
+func CalculateTau(precision int64, pi *Pi) error {
+	return CalculateTauContext(context.Background(), precision, pi)
+}
+
+// CalculateTauContext is like CalculateTau but stops early if ctx is cancelled
+func CalculateTauContext(ctx context.Context, precision int64, pi *Pi) error {
+	if err := checkPrecision(precision, pi); err != nil {
+		return err
+	}
+	return calculateDecimal(ctx, precision, pi, doubleDecimal)
+}
+
+// doubleDecimal doubles a non-negative decimal string such as "3.14159"
+func doubleDecimal(s string) string {
+	out := []byte(s)
+	carry := byte(0)
+
+	for i := len(out) - 1; i >= 0; i-- {
+		if out[i] == '.' {
+			continue
+		}
+		d := (out[i]-'0')*2 + carry
+		out[i] = '0' + d%10
+		carry = d / 10
+	}
+
+	if carry > 0 {
+		return string('0'+carry) + string(out)
+	}
+	return string(out)
+}
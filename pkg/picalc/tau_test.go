@@ -0,0 +1,59 @@
+package picalc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCalculateTau(t *testing.T) {
+	expected := []int{6, 2, 8, 3, 1, 8, 5, 3, 0, 7, 1, 7, 9, 5, 8, 6, 4, 7, 6, 9, 2, 5, 2, 8, 6, 7, 6, 6, 5, 5, 9}
+
+	for _, precision := range []int64{8, 30} {
+		pi := NewPi(precision)
+		if err := CalculateTau(precision, pi); err != nil {
+			t.Fatalf("Failed to calculate tau: %v", err)
+		}
+
+		n := int(precision) + 1
+		if digits := pi.GetDigits(n); !reflect.DeepEqual(digits, expected[:n]) {
+			t.Errorf("Tau digits mismatch at precision %d.\nExpected: %v\nGot: %v", precision, expected[:n], digits)
+		}
+	}
+
+	// Formatting follows the integer part
+	pi := NewPi(9)
+	CalculateTau(9, pi)
+	if got := FormatGrouped(pi.GetDigits(10), 0, ""); got != "6.283185307" {
+		t.Errorf("Expected 6.283185307, got %s", got)
+	}
+}
+
+func TestDoubleDecimal(t *testing.T) {
+	tests := map[string]string{
+		"3.14159": "6.28318",
+		"0.5":     "1.0",
+		"4.99":    "9.98",
+		"5.5":     "11.0",
+	}
+
+	for in, expected := range tests {
+		if got := doubleDecimal(in); got != expected {
+			t.Errorf("doubleDecimal(%q) = %q, expected %q", in, got, expected)
+		}
+	}
+}
+
+func TestCalculateTauContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pi := NewPi(3000)
+	if err := CalculateTauContext(ctx, 3000, pi); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got: %v", err)
+	}
+	if progress := pi.GetProgress(); progress >= 100.0 {
+		t.Errorf("A cancelled calculation should not report completion, got %f", progress)
+	}
+}
@@ -0,0 +1,73 @@
+package picalc
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RangeTrace records which worker summed a range of series terms and how
+// long it took, see Options.Trace
+type RangeTrace struct {
+	Start, End int64 // the terms [Start, End)
+	Worker     int   // 0 for the calling goroutine, then numbered as workers start
+	Duration   time.Duration
+}
+
+// traceKey and workerKey hold the tracer and the current worker id in a context
+type traceKey struct{}
+type workerKey struct{}
+
+// tracer collects RangeTraces from every worker of a calculation
+type tracer struct {
+	mutex   sync.Mutex
+	ranges  []RangeTrace
+	workers atomic.Int64
+}
+
+// withTracer returns a context that records ranges to a new tracer
+func withTracer(ctx context.Context) (context.Context, *tracer) {
+	t := &tracer{}
+	return context.WithValue(ctx, traceKey{}, t), t
+}
+
+// spawnWorker returns the context for a new worker goroutine, giving it its
+// own id when tracing
+func spawnWorker(ctx context.Context) context.Context {
+	t, _ := ctx.Value(traceKey{}).(*tracer)
+	if t == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, workerKey{}, int(t.workers.Add(1)))
+}
+
+// traceRange runs split, which sums the terms [a, b), recording it when tracing
+func traceRange(ctx context.Context, a, b int64, split func()) {
+	t, _ := ctx.Value(traceKey{}).(*tracer)
+	if t == nil {
+		split()
+		return
+	}
+
+	worker, _ := ctx.Value(workerKey{}).(int)
+	start := time.Now()
+	split()
+	elapsed := time.Since(start)
+
+	t.mutex.Lock()
+	t.ranges = append(t.ranges, RangeTrace{Start: a, End: b, Worker: worker, Duration: elapsed})
+	t.mutex.Unlock()
+}
+
+// LastTrace returns the term ranges summed serially during the last
+// calculation, including its verification run, and which worker summed each.
+// It is empty unless Options.Trace was set.
+func (p *Pi) LastTrace() []RangeTrace {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	trace := make([]RangeTrace, len(p.trace))
+	copy(trace, p.trace)
+	return trace
+}
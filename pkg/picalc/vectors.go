@@ -0,0 +1,21 @@
+package picalc
+
+// GenerateVectors returns Pi to every precision from 1 to max decimal places,
+// keyed by precision, e.g. 2 -> "3.14", for use as golden test data by other
+// implementations. Digits are truncated, so every one is a true digit of Pi.
+// The result holds O(max²) bytes, so keep max modest.
+func GenerateVectors(max int64) map[int64]string {
+	vectors := make(map[int64]string)
+	if max < 1 {
+		return vectors
+	}
+
+	pi := NewPi(max)
+	CalculatePi(max, pi)
+	full := pi.GetString(int(max) + 1)
+
+	for precision := int64(1); precision <= max; precision++ {
+		vectors[precision] = full[:precision+2] // "3." and the digits
+	}
+	return vectors
+}
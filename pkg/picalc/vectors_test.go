@@ -0,0 +1,21 @@
+package picalc
+
+import "testing"
+
+func TestGenerateVectors(t *testing.T) {
+	vectors := GenerateVectors(300)
+	if len(vectors) != 300 {
+		t.Fatalf("Expected 300 vectors, got %d", len(vectors))
+	}
+
+	for precision, digits := range vectors {
+		expected := "3." + piReference[1:precision+1]
+		if digits != expected {
+			t.Errorf("Precision %d: expected %s, got %s", precision, expected, digits)
+		}
+	}
+
+	if vectors := GenerateVectors(0); len(vectors) != 0 {
+		t.Errorf("Expected no vectors for max 0, got %d", len(vectors))
+	}
+}
@@ -0,0 +1,58 @@
+package picalc
+
+import (
+	"context"
+	"fmt"
+)
+
+// ComputeVerified calculates precision decimal places of Pi twice, with the
+// Chudnovsky and then the Ramanujan series, and returns only the leading
+// digits on which both agree along with how many there are, counting the 3.
+// Two independent series agreeing guards against a bug or hardware fault in
+// either one; a normal run agrees on all precision+1 digits.
+func ComputeVerified(precision int64) ([]int, int, error) {
+	if precision < 0 {
+		return nil, 0, fmt.Errorf("precision must be non-negative, got %d", precision)
+	}
+
+	pi := NewPiWithOptions(precision, Options{Fast: true, NoHardcode: true})
+	if err := CalculatePiContext(context.Background(), precision, pi); err != nil {
+		return nil, 0, err
+	}
+
+	agreed, err := CrossCheck(context.Background(), pi)
+	if err != nil {
+		return nil, 0, err
+	}
+	return pi.GetDigits(agreed), agreed, nil
+}
+
+// CrossCheck recomputes the digits of a completed pi with the other
+// algorithm, Ramanujan for Chudnovsky and vice versa, and returns how many
+// leading digits, counting the 3, the two calculations agree on. The
+// recomputation skips verification and never uses the hardcoded value,
+// since only agreement with pi matters.
+func CrossCheck(ctx context.Context, pi *Pi) (int, error) {
+	if !pi.IsComplete() {
+		return 0, fmt.Errorf("cross-check: calculation has not completed")
+	}
+
+	opts := Options{
+		Algorithm:  Ramanujan,
+		Rounding:   pi.opts.Rounding,
+		Fast:       true,
+		NoHardcode: true,
+	}
+	if pi.algorithm == Ramanujan {
+		opts.Algorithm = Chudnovsky
+	}
+
+	precision := pi.currentPrecision()
+	check := NewPiWithOptions(precision, opts)
+	if err := CalculatePiContext(ctx, precision, check); err != nil {
+		return 0, fmt.Errorf("cross-check with %s: %w", opts.Algorithm, err)
+	}
+
+	_, agreed := DigitsEqual(pi.GetDigits(pi.numDigits()), check.GetDigits(check.numDigits()))
+	return agreed, nil
+}
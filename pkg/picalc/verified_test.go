@@ -0,0 +1,62 @@
+package picalc
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestComputeVerified(t *testing.T) {
+	for _, precision := range []int64{5, 100, 1000} {
+		digits, agreed, err := ComputeVerified(precision)
+		if err != nil {
+			t.Fatalf("Precision %d: %v", precision, err)
+		}
+		if agreed < int(precision) || len(digits) != agreed {
+			t.Errorf("Precision %d: agreed on %d digits, returned %d", precision, agreed, len(digits))
+		}
+
+		pi := NewPi(precision)
+		CalculatePi(precision, pi)
+		if !reflect.DeepEqual(digits, pi.GetDigits(agreed)) {
+			t.Errorf("Precision %d: verified digits differ from CalculatePi", precision)
+		}
+	}
+
+	if _, _, err := ComputeVerified(-1); err == nil {
+		t.Error("Expected an error for a negative precision")
+	}
+}
+
+func TestComputeVerifiedDisagreement(t *testing.T) {
+	orig := splitRoot
+	defer func() { splitRoot = orig }()
+
+	// Perturb the Chudnovsky sum by a relative 2^-200, about 60 digits in
+	splitRoot = func(ctx context.Context, a, b int64, A, B, C3_24 *big.Int, done *atomic.Int64, parallel bool) (*big.Int, *big.Int, error) {
+		Q, R, err := orig(ctx, a, b, A, B, C3_24, done, parallel)
+		if err != nil {
+			return nil, nil, err
+		}
+		return Q, new(big.Int).Add(R, new(big.Int).Rsh(R, 200)), nil
+	}
+
+	digits, agreed, err := ComputeVerified(200)
+	if err != nil {
+		t.Fatalf("ComputeVerified failed: %v", err)
+	}
+	if agreed < 50 || agreed > 70 {
+		t.Errorf("Expected agreement on about 60 digits, got %d", agreed)
+	}
+	if len(digits) != agreed {
+		t.Errorf("Expected %d digits, got %d", agreed, len(digits))
+	}
+}
+
+func TestCrossCheckIncomplete(t *testing.T) {
+	if _, err := CrossCheck(context.Background(), NewPi(100)); err == nil {
+		t.Error("Expected an error for a Pi that hasn't been calculated")
+	}
+}
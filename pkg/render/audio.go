@@ -0,0 +1,109 @@
+package render
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	// SampleRate is the number of samples per second in rendered audio
+	SampleRate = 22050
+
+	// NoteDuration is how long each digit's note sounds
+	NoteDuration = 250 * time.Millisecond
+
+	// SamplesPerNote is the number of samples rendered for each digit
+	SamplesPerNote = int(SampleRate * NoteDuration / time.Second)
+
+	// fadeSamples ramps each note in and out so notes don't click
+	fadeSamples = SampleRate / 200
+)
+
+// Notes maps each decimal digit 0-9 to a MIDI note number, two octaves of
+// the C major pentatonic scale from middle C, which sounds consonant
+// whatever order the digits come in
+var Notes = [10]int{60, 62, 64, 67, 69, 72, 74, 76, 79, 81}
+
+// noteFrequency returns the frequency in Hz of a MIDI note in equal temperament
+func noteFrequency(note int) float64 {
+	return 440 * math.Pow(2, float64(note-69)/12)
+}
+
+// RenderDigitsWAV writes digits as a mono 16-bit PCM WAV file, playing the
+// note Notes[d] for NoteDuration for each digit d in order
+func RenderDigitsWAV(digits []int, w io.Writer) error {
+	for i, d := range digits {
+		if d < 0 || d > 9 {
+			return fmt.Errorf("invalid digit %d at position %d", d, i)
+		}
+	}
+
+	const bytesPerSample = 2
+	dataSize := uint64(len(digits)) * uint64(SamplesPerNote) * bytesPerSample
+	if dataSize > math.MaxUint32-36 {
+		return fmt.Errorf("%d digits exceed the 4 GiB WAV size limit", len(digits))
+	}
+
+	header := struct {
+		RIFF          [4]byte
+		ChunkSize     uint32
+		WAVE          [4]byte
+		Fmt           [4]byte
+		FmtSize       uint32
+		AudioFormat   uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+		Data          [4]byte
+		DataSize      uint32
+	}{
+		RIFF:          [4]byte{'R', 'I', 'F', 'F'},
+		ChunkSize:     36 + uint32(dataSize),
+		WAVE:          [4]byte{'W', 'A', 'V', 'E'},
+		Fmt:           [4]byte{'f', 'm', 't', ' '},
+		FmtSize:       16,
+		AudioFormat:   1, // PCM
+		Channels:      1,
+		SampleRate:    SampleRate,
+		ByteRate:      SampleRate * bytesPerSample,
+		BlockAlign:    bytesPerSample,
+		BitsPerSample: 8 * bytesPerSample,
+		Data:          [4]byte{'d', 'a', 't', 'a'},
+		DataSize:      uint32(dataSize),
+	}
+	if err := binary.Write(w, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+
+	// Each note is rendered once and reused for every digit that plays it
+	var notes [10][]byte
+	for d, note := range Notes {
+		notes[d] = renderNote(noteFrequency(note))
+	}
+
+	for _, d := range digits {
+		if _, err := w.Write(notes[d]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderNote returns SamplesPerNote little-endian 16-bit samples of a sine
+// wave at freq Hz, faded in and out over fadeSamples
+func renderNote(freq float64) []byte {
+	const amplitude = 0.5 * math.MaxInt16
+
+	buf := make([]byte, 2*SamplesPerNote)
+	for i := 0; i < SamplesPerNote; i++ {
+		gain := min(1, float64(i)/fadeSamples, float64(SamplesPerNote-1-i)/fadeSamples)
+		sample := amplitude * gain * math.Sin(2*math.Pi*freq*float64(i)/SampleRate)
+		binary.LittleEndian.PutUint16(buf[2*i:], uint16(int16(sample)))
+	}
+	return buf
+}
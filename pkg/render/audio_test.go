@@ -0,0 +1,68 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestRenderDigitsWAV(t *testing.T) {
+	digits := []int{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5}
+
+	var buf bytes.Buffer
+	if err := RenderDigitsWAV(digits, &buf); err != nil {
+		t.Fatalf("RenderDigitsWAV failed: %v", err)
+	}
+	data := buf.Bytes()
+	if len(data) < 44 {
+		t.Fatalf("Output is shorter than a WAV header: %d bytes", len(data))
+	}
+
+	le := binary.LittleEndian
+	if string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		t.Errorf("Missing RIFF/WAVE magic: %q", data[:12])
+	}
+	if got := le.Uint32(data[4:8]); int(got) != len(data)-8 {
+		t.Errorf("RIFF chunk size %d, expected %d", got, len(data)-8)
+	}
+	if string(data[12:16]) != "fmt " || le.Uint32(data[16:20]) != 16 {
+		t.Errorf("Invalid fmt chunk: %q size %d", data[12:16], le.Uint32(data[16:20]))
+	}
+	if format, channels := le.Uint16(data[20:22]), le.Uint16(data[22:24]); format != 1 || channels != 1 {
+		t.Errorf("Expected mono PCM, got format %d with %d channels", format, channels)
+	}
+	if rate := le.Uint32(data[24:28]); rate != SampleRate {
+		t.Errorf("Sample rate %d, expected %d", rate, SampleRate)
+	}
+	if bits := le.Uint16(data[34:36]); bits != 16 {
+		t.Errorf("Expected 16 bits per sample, got %d", bits)
+	}
+	if string(data[36:40]) != "data" {
+		t.Errorf("Missing data chunk: %q", data[36:40])
+	}
+
+	// One note of SamplesPerNote 16-bit samples per digit
+	samples := len(digits) * SamplesPerNote
+	if got := le.Uint32(data[40:44]); int(got) != 2*samples {
+		t.Errorf("Data size %d, expected %d", got, 2*samples)
+	}
+	if len(data)-44 != 2*samples {
+		t.Errorf("Wrote %d bytes of samples, expected %d", len(data)-44, 2*samples)
+	}
+
+	// Equal digits play identical notes, different ones don't
+	note := func(i int) []byte {
+		start := 44 + 2*i*SamplesPerNote
+		return data[start : start+2*SamplesPerNote]
+	}
+	if !bytes.Equal(note(1), note(3)) {
+		t.Error("Both 1s should render the same note")
+	}
+	if bytes.Equal(note(0), note(1)) {
+		t.Error("3 and 1 should render different notes")
+	}
+
+	if err := RenderDigitsWAV([]int{3, 10}, &buf); err == nil {
+		t.Error("Expected an error for an invalid digit")
+	}
+}
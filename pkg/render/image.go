@@ -0,0 +1,37 @@
+// Package render turns digits of Pi into images and other media
+package render
+
+import (
+	"image"
+	"image/color"
+)
+
+// Palette maps each decimal digit 0-9 to a color
+var Palette = [10]color.RGBA{
+	{0x1f, 0x1f, 0x1f, 0xff}, // 0
+	{0xe6, 0x19, 0x4b, 0xff}, // 1
+	{0xf5, 0x82, 0x31, 0xff}, // 2
+	{0xff, 0xe1, 0x19, 0xff}, // 3
+	{0xbf, 0xef, 0x45, 0xff}, // 4
+	{0x3c, 0xb4, 0x4b, 0xff}, // 5
+	{0x42, 0xd4, 0xf4, 0xff}, // 6
+	{0x43, 0x63, 0xd8, 0xff}, // 7
+	{0x91, 0x1e, 0xb4, 0xff}, // 8
+	{0xf0, 0xf0, 0xf0, 0xff}, // 9
+}
+
+// RenderDigitsImage draws one pixel per digit, left to right and top to bottom,
+// in rows of width pixels. Pixels past the last digit are left transparent.
+func RenderDigitsImage(digits []int, width int) image.Image {
+	if width <= 0 {
+		width = 1
+	}
+	height := (len(digits) + width - 1) / width
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i, d := range digits {
+		img.SetRGBA(i%width, i/width, Palette[d%10])
+	}
+
+	return img
+}
@@ -0,0 +1,34 @@
+package render
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestRenderDigitsImage(t *testing.T) {
+	digits := []int{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5}
+
+	img := RenderDigitsImage(digits, 4)
+	if b := img.Bounds(); b.Dx() != 4 || b.Dy() != 3 {
+		t.Fatalf("Expected a 4x3 image, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	// Pixels follow the digit order
+	if got := img.At(1, 1); got != Palette[9] {
+		t.Errorf("Pixel (1, 1) should be the color for 9, got %v", got)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+
+	decoded, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Generated PNG is not decodable: %v", err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Errorf("Decoded bounds %v differ from %v", decoded.Bounds(), img.Bounds())
+	}
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/shammianand/picalc/pkg/picalc"
+)
+
+// handleHex serves GET /pi/hex?n=K as the single hex digit of π at position
+// K after the point, computed with BBP in constant memory and without any
+// of the preceding digits, for clients spot-checking distant digits
+func (s *Server) handleHex(w http.ResponseWriter, r *http.Request) {
+	s.metrics.requests.WithLabelValues("hex").Inc()
+
+	n, err := strconv.ParseInt(r.URL.Query().Get("n"), 10, 64)
+	if err != nil {
+		http.Error(w, "n must be a valid integer", http.StatusBadRequest)
+		return
+	}
+	if n < 0 || n >= s.maxDigits {
+		http.Error(w, fmt.Sprintf("n must be between 0 and %d", s.maxDigits-1), http.StatusBadRequest)
+		return
+	}
+
+	digit, err := picalc.NthHexDigit(n)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte{digit})
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metrics are the Prometheus collectors for one Server. Each Server has its
+// own registry so several can run in one process, e.g. in tests.
+type metrics struct {
+	registry    *prometheus.Registry
+	requests    *prometheus.CounterVec
+	digits      prometheus.Counter
+	duration    prometheus.Histogram
+	cacheHits   prometheus.Counter
+	cacheMisses prometheus.Counter
+
+	// Mirrors of the cache counters for computing the hit ratio
+	hits, misses atomic.Int64
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "picalc_requests_total",
+			Help: "Requests served, by endpoint.",
+		}, []string{"endpoint"}),
+		digits: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "picalc_digits_computed_total",
+			Help: "Decimal digits computed, excluding cache hits.",
+		}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "picalc_computation_duration_seconds",
+			Help:    "Time spent computing π.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 10),
+		}),
+		cacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "picalc_cache_hits_total",
+			Help: "Requests answered from the cache.",
+		}),
+		cacheMisses: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "picalc_cache_misses_total",
+			Help: "Requests that required a computation.",
+		}),
+	}
+
+	hitRatio := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "picalc_cache_hit_ratio",
+		Help: "Fraction of lookups answered from the cache.",
+	}, m.hitRatio)
+
+	m.registry.MustRegister(m.requests, m.digits, m.duration, m.cacheHits, m.cacheMisses, hitRatio)
+	return m
+}
+
+// cacheHit records a lookup answered from the cache
+func (m *metrics) cacheHit() {
+	m.hits.Add(1)
+	m.cacheHits.Inc()
+}
+
+// cacheMiss records a lookup that required a computation
+func (m *metrics) cacheMiss() {
+	m.misses.Add(1)
+	m.cacheMisses.Inc()
+}
+
+// hitRatio returns cache hits over lookups, or 0 before any lookup
+func (m *metrics) hitRatio() float64 {
+	hits := m.hits.Load()
+	total := hits + m.misses.Load()
+	if total == 0 {
+		return 0
+	}
+	return float64(hits) / float64(total)
+}
+
+// handler serves the metrics in the Prometheus exposition format
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,191 @@
+// Package server exposes π computation over HTTP
+package server
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shammianand/picalc/pkg/picalc"
+)
+
+// DefaultMaxDigits bounds the precision a single request may ask for
+const DefaultMaxDigits = 1_000_000
+
+// DefaultCacheDigits bounds the total precision of the cached computations.
+// The least recently used are evicted beyond it.
+const DefaultCacheDigits = 4 * DefaultMaxDigits
+
+// Server serves digits of π and caches completed computations by precision
+type Server struct {
+	mux         *http.ServeMux
+	maxDigits   int64
+	cacheDigits int64
+	metrics     *metrics
+
+	mutex        sync.Mutex
+	cache        map[int64]*list.Element
+	lru          *list.List // of *cacheEntry, most recently used first
+	cachedDigits int64
+}
+
+// cacheEntry is a cached computation of π to precision digits
+type cacheEntry struct {
+	precision int64
+	pi        *picalc.Pi
+}
+
+// New creates a Server that accepts requests for up to maxDigits digits.
+// A maxDigits of 0 or less uses DefaultMaxDigits. The cache holds up to
+// DefaultCacheDigits digits, or one computation at maxDigits if that is more.
+func New(maxDigits int64) *Server {
+	if maxDigits <= 0 {
+		maxDigits = DefaultMaxDigits
+	}
+
+	s := &Server{
+		mux:         http.NewServeMux(),
+		maxDigits:   maxDigits,
+		cacheDigits: max(DefaultCacheDigits, maxDigits),
+		cache:       make(map[int64]*list.Element),
+		lru:         list.New(),
+	}
+	s.metrics = newMetrics()
+
+	s.mux.HandleFunc("GET /pi", s.handlePi)
+	s.mux.HandleFunc("GET /pi/stream", s.handleStream)
+	s.mux.HandleFunc("GET /pi/hex", s.handleHex)
+	s.mux.Handle("GET /metrics", s.metrics.handler())
+
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handlePi serves GET /pi?digits=N as plain text "3.1415..."
+func (s *Server) handlePi(w http.ResponseWriter, r *http.Request) {
+	s.metrics.requests.WithLabelValues("pi").Inc()
+
+	digits, err := s.parseDigits(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	pi, err := s.compute(r.Context(), digits)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	picalc.WriteDigitsText(w, pi.GetDigits(int(digits)+1), picalc.TextOptions{})
+}
+
+// parseDigits reads and bounds-checks the digits query parameter
+func (s *Server) parseDigits(r *http.Request) (int64, error) {
+	digits, err := strconv.ParseInt(r.URL.Query().Get("digits"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("digits must be a valid integer")
+	}
+	if digits < 1 || digits > s.maxDigits {
+		return 0, fmt.Errorf("digits must be between 1 and %d", s.maxDigits)
+	}
+	return digits, nil
+}
+
+// precisionStep is the granularity at which precisions are computed and
+// cached, so nearby requests share one calculation
+const precisionStep = 1000
+
+// normalizePrecision rounds n up to the next multiple of precisionStep
+func normalizePrecision(n int64) int64 {
+	if n <= 0 {
+		return precisionStep
+	}
+	return (n + precisionStep - 1) / precisionStep * precisionStep
+}
+
+// cachePrecision returns the precision computed and cached to serve a
+// request for digits: normalizePrecision(digits), but never beyond
+// maxDigits. Handlers slice the result to the digits requested.
+func (s *Server) cachePrecision(digits int64) int64 {
+	return max(min(normalizePrecision(digits), s.maxDigits), digits)
+}
+
+// compute returns Pi to at least the given precision, from the cache if possible
+func (s *Server) compute(ctx context.Context, digits int64) (*picalc.Pi, error) {
+	precision := s.cachePrecision(digits)
+	if pi, ok := s.cached(precision); ok {
+		return pi, nil
+	}
+
+	pi := picalc.NewPi(precision)
+	if err := s.calculate(ctx, precision, pi); err != nil {
+		return nil, err
+	}
+	return pi, nil
+}
+
+// cached returns the cached Pi for the given precision, recording the lookup
+func (s *Server) cached(digits int64) (*picalc.Pi, bool) {
+	s.mutex.Lock()
+	var pi *picalc.Pi
+	e, ok := s.cache[digits]
+	if ok {
+		s.lru.MoveToFront(e)
+		pi = e.Value.(*cacheEntry).pi
+	}
+	s.mutex.Unlock()
+
+	if ok {
+		s.metrics.cacheHit()
+	} else {
+		s.metrics.cacheMiss()
+	}
+	return pi, ok
+}
+
+// store caches pi at the given precision, evicting the least recently used
+// computations while the cache holds more than cacheDigits digits. The newest
+// is always kept. The caller must hold s.mutex.
+func (s *Server) store(digits int64, pi *picalc.Pi) {
+	if e, ok := s.cache[digits]; ok {
+		// A concurrent request computed the same precision
+		e.Value.(*cacheEntry).pi = pi
+		s.lru.MoveToFront(e)
+		return
+	}
+
+	s.cache[digits] = s.lru.PushFront(&cacheEntry{precision: digits, pi: pi})
+	s.cachedDigits += digits
+
+	for s.cachedDigits > s.cacheDigits && s.lru.Len() > 1 {
+		oldest := s.lru.Remove(s.lru.Back()).(*cacheEntry)
+		delete(s.cache, oldest.precision)
+		s.cachedDigits -= oldest.precision
+	}
+}
+
+// calculate computes pi to the given precision and caches it on success
+func (s *Server) calculate(ctx context.Context, digits int64, pi *picalc.Pi) error {
+	start := time.Now()
+	if err := picalc.CalculatePiContext(ctx, digits, pi); err != nil {
+		return err
+	}
+	s.metrics.duration.Observe(time.Since(start).Seconds())
+	s.metrics.digits.Add(float64(digits))
+
+	s.mutex.Lock()
+	s.store(digits, pi)
+	s.mutex.Unlock()
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestPiEndpoint(t *testing.T) {
+	ts := httptest.NewServer(New(1000))
+	defer ts.Close()
+
+	status, body := get(t, ts.URL+"/pi?digits=20")
+	if status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", status, body)
+	}
+	if body != "3.14159265358979323846" {
+		t.Errorf("Unexpected digits: %s", body)
+	}
+
+	for _, q := range []string{"", "digits=abc", "digits=0", "digits=1001"} {
+		if status, _ := get(t, ts.URL+"/pi?"+q); status != http.StatusBadRequest {
+			t.Errorf("Query %q: expected 400, got %d", q, status)
+		}
+	}
+}
+
+func TestNormalizePrecision(t *testing.T) {
+	for n, expected := range map[int64]int64{0: 1000, 1: 1000, 950: 1000, 1000: 1000, 1001: 2000} {
+		if got := normalizePrecision(n); got != expected {
+			t.Errorf("normalizePrecision(%d): expected %d, got %d", n, expected, got)
+		}
+	}
+
+	// Never beyond the server's limit
+	if got := New(1500).cachePrecision(1200); got != 1500 {
+		t.Errorf("Expected 1500 at the limit, got %d", got)
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	s := New(0)
+	s.cacheDigits = 4000
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	// Using 1000 again makes 2000 the least recently used, so adding 3000
+	// evicts only 2000
+	for _, q := range []string{"1000", "2000", "1000", "3000"} {
+		if status, body := get(t, ts.URL+"/pi?digits="+q); status != http.StatusOK {
+			t.Fatalf("digits=%s: expected 200, got %d: %s", q, status, body)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, short := s.cache[1000]
+	_, long := s.cache[3000]
+	if !short || !long || len(s.cache) != 2 || s.cachedDigits != 4000 {
+		t.Errorf("Expected the 1000 and 3000 digit calculations cached, got %d entries of %d digits", len(s.cache), s.cachedDigits)
+	}
+
+	// The newest is kept even when it alone exceeds the limit
+	s.cacheDigits = 1000
+	s.store(5000, nil)
+	if _, ok := s.cache[5000]; !ok || len(s.cache) != 1 || s.lru.Len() != 1 {
+		t.Errorf("Expected only the newest calculation cached, got %d entries", len(s.cache))
+	}
+}
+
+func TestNearbyRequestsShareCache(t *testing.T) {
+	s := New(0)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	_, short := get(t, ts.URL+"/pi?digits=950")
+	_, long := get(t, ts.URL+"/pi?digits=1000")
+	if len(short) != 952 || len(long) != 1002 || !strings.HasPrefix(long, short) {
+		t.Errorf("Expected 950 and 1000 digits sharing a prefix, got %d and %d bytes", len(short), len(long))
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.cache[1000]; !ok || len(s.cache) != 1 {
+		t.Errorf("Expected one cached 1000 digit calculation, got %d entries", len(s.cache))
+	}
+	if hits, misses := s.metrics.hits.Load(), s.metrics.misses.Load(); hits != 1 || misses != 1 {
+		t.Errorf("Expected 1 hit and 1 miss, got %d and %d", hits, misses)
+	}
+}
+
+func TestHexEndpoint(t *testing.T) {
+	ts := httptest.NewServer(New(1000))
+	defer ts.Close()
+
+	// π = 3.243F6A88...
+	for q, expected := range map[string]string{"n=0": "2", "n=3": "F", "n=7": "8"} {
+		status, body := get(t, ts.URL+"/pi/hex?"+q)
+		if status != http.StatusOK || body != expected {
+			t.Errorf("Query %q: expected 200 %q, got %d %q", q, expected, status, body)
+		}
+	}
+
+	for _, q := range []string{"", "n=abc", "n=-1", "n=1000"} {
+		if status, _ := get(t, ts.URL+"/pi/hex?"+q); status != http.StatusBadRequest {
+			t.Errorf("Query %q: expected 400, got %d", q, status)
+		}
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	ts := httptest.NewServer(New(0))
+	defer ts.Close()
+
+	// The second request is served from the cache, and both are rounded up
+	// to a 1000 digit calculation
+	get(t, ts.URL+"/pi?digits=100")
+	get(t, ts.URL+"/pi?digits=100")
+
+	status, body := get(t, ts.URL+"/metrics")
+	if status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+
+	for _, expected := range []string{
+		`picalc_requests_total{endpoint="pi"} 2`,
+		"picalc_digits_computed_total 1000\n",
+		`picalc_computation_duration_seconds_count 1`,
+		`picalc_cache_hits_total 1`,
+		`picalc_cache_misses_total 1`,
+		`picalc_cache_hit_ratio 0.5`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Metrics missing %q", expected)
+		}
+	}
+}
+
+func TestStreamEndpoint(t *testing.T) {
+	ts := httptest.NewServer(New(0))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/pi/stream?digits=2000")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %q", ct)
+	}
+
+	// Collect events, each an "event:" line followed by a "data:" line
+	var progressEvents int
+	var result string
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 1<<20)
+	var event string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data := strings.TrimPrefix(line, "data: ")
+			switch event {
+			case "progress":
+				progressEvents++
+			case "result":
+				result = data
+			case "error":
+				t.Fatalf("Unexpected error event: %s", data)
+			}
+		}
+	}
+
+	if progressEvents == 0 {
+		t.Error("Expected at least one progress event")
+	}
+	if !strings.HasPrefix(result, "3.14159265358979") || len(result) != 2002 {
+		t.Errorf("Unexpected result event: %.20s... (%d bytes)", result, len(result))
+	}
+
+	if status, _ := get(t, ts.URL+"/pi/stream?digits=0"); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid precision, got %d", status)
+	}
+}
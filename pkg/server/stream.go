@@ -0,0 +1,74 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/shammianand/picalc/pkg/picalc"
+)
+
+// progressInterval is how often GET /pi/stream reports progress
+const progressInterval = 100 * time.Millisecond
+
+// handleStream serves GET /pi/stream?digits=N as server-sent events: a
+// "progress" event with the percentage completed every progressInterval,
+// then a "result" event with "3.1415..." or an "error" event
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	s.metrics.requests.WithLabelValues("stream").Inc()
+
+	digits, err := s.parseDigits(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	rc := http.NewResponseController(w)
+
+	send := func(event, data string) {
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+		rc.Flush()
+	}
+
+	precision := s.cachePrecision(digits)
+	pi, ok := s.cached(precision)
+	if !ok {
+		pi = picalc.NewPi(precision)
+		done := make(chan error, 1)
+		go func() {
+			done <- s.calculate(r.Context(), precision, pi)
+		}()
+
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+
+		send("progress", formatProgress(pi.GetProgress()))
+	wait:
+		for {
+			select {
+			case err := <-done:
+				if err != nil {
+					send("error", err.Error())
+					return
+				}
+				break wait
+			case <-ticker.C:
+				send("progress", formatProgress(pi.GetProgress()))
+			}
+		}
+	}
+
+	send("progress", formatProgress(100))
+
+	var result bytes.Buffer
+	picalc.WriteDigitsText(&result, pi.GetDigits(int(digits)+1), picalc.TextOptions{})
+	send("result", result.String())
+}
+
+// formatProgress formats a progress percentage for an event
+func formatProgress(progress float64) string {
+	return fmt.Sprintf("%.1f", progress)
+}